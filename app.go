@@ -0,0 +1,206 @@
+package compcont
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+// 应用入口，将配置来源、工厂注册器、根容器、生命周期回调与信号处理组合在一起
+type App struct {
+	Name     string           // 应用名称，用于命令行帮助
+	Source   IConfigSource    // 配置来源，不填时从ConfigPaths中的文件加载
+	Registry IFactoryRegistry // 工厂注册器，不填为DefaultFactoryRegistry
+	Options  []Option         // 创建根容器时的额外选项
+	Shutdown ShutdownOptions  // 信号与停机选项
+	Watch    bool             // 是否监听配置来源的变化并热更新
+	Stdout   io.Writer        // dry-run计划的输出，不填为os.Stdout
+	Stderr   io.Writer        // 错误信息的输出，不填为os.Stderr
+
+	ConfigPaths []string // 配置文件或目录，对应命令行参数-config
+	Profiles    []string // 启用的profile，对应命令行参数-profile
+	DryRun      bool     // 只输出加载计划而不创建组件，对应命令行参数-dry-run
+
+	OnLoaded   func(ctx context.Context, container IComponentContainer) error // 组件加载完成、启动之前的回调，返回错误时中止运行
+	OnStarted  func(ctx context.Context, container IComponentContainer)       // 组件启动完成后的回调
+	OnStopping func(ctx context.Context, container IComponentContainer)       // 开始停机时的回调
+}
+
+// 以逗号分隔、可重复指定的字符串列表参数，首次指定时替换预设的值
+type stringsFlag struct {
+	values *[]string
+	set    bool
+}
+
+func (f *stringsFlag) String() string {
+	if f == nil || f.values == nil {
+		return ""
+	}
+	return strings.Join(*f.values, ",")
+}
+
+func (f *stringsFlag) Set(s string) error {
+	if !f.set {
+		*f.values, f.set = nil, true
+	}
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			*f.values = append(*f.values, v)
+		}
+	}
+	return nil
+}
+
+// RegisterFlags 将-config、-profile与-dry-run参数注册到fs
+func (a *App) RegisterFlags(fs *flag.FlagSet) {
+	fs.Var(&stringsFlag{values: &a.ConfigPaths}, "config", "config file or directory, comma separated or repeated")
+	fs.Var(&stringsFlag{values: &a.Profiles}, "profile", "active profiles, comma separated or repeated")
+	fs.BoolVar(&a.DryRun, "dry-run", a.DryRun, "print the load plan without creating components")
+}
+
+// Run 解析命令行参数并运行应用，返回值为进程应使用的退出码，通常的用法为os.Exit(app.Run())
+func (a *App) Run() int {
+	return a.RunContext(context.Background(), os.Args[1:])
+}
+
+// RunContext 解析args中的命令行参数，加载配置与组件，启动后等待信号或ctx结束，随后停止并销毁组件。
+// 参数与默认值只作用于本次运行，不会修改a，同一个App可以多次运行
+func (a *App) RunContext(ctx context.Context, args []string) int {
+	run := *a
+	return run.run(ctx, args)
+}
+
+func (a *App) run(ctx context.Context, args []string) int {
+	if a.Stdout == nil {
+		a.Stdout = os.Stdout
+	}
+	if a.Stderr == nil {
+		a.Stderr = os.Stderr
+	}
+	fs := flag.NewFlagSet(a.Name, flag.ContinueOnError)
+	fs.SetOutput(a.Stderr)
+	a.RegisterFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return ExitOK
+		}
+		return ExitShutdownFailed
+	}
+
+	container, configs, err := a.load(ctx)
+	if err != nil {
+		fmt.Fprintln(a.Stderr, err)
+		return ExitShutdownFailed
+	}
+	if a.DryRun {
+		if err = a.printPlan(container, configs); err != nil {
+			fmt.Fprintln(a.Stderr, err)
+			return ExitShutdownFailed
+		}
+		return ExitOK
+	}
+
+	if err = container.LoadNamedComponentsContext(ctx, configs); err != nil {
+		fmt.Fprintln(a.Stderr, fmt.Errorf("load components: %w", err))
+		if unloadErr := unloadAll(container); unloadErr != nil {
+			fmt.Fprintln(a.Stderr, fmt.Errorf("destroy components: %w", unloadErr))
+		}
+		return ExitShutdownFailed
+	}
+	if a.OnLoaded != nil {
+		if err = a.OnLoaded(ctx, container); err != nil {
+			fmt.Fprintln(a.Stderr, err)
+			if unloadErr := unloadAll(container); unloadErr != nil {
+				fmt.Fprintln(a.Stderr, fmt.Errorf("destroy components: %w", unloadErr))
+			}
+			return ExitShutdownFailed
+		}
+	}
+
+	// 热更新只在运行期间进行，开始停机时停止监听
+	watchCtx, stopWatch := context.WithCancel(ctx)
+	defer stopWatch()
+	var stopping sync.Once
+	opt := a.Shutdown
+	opt.OnStarted = func() {
+		if a.Watch {
+			watcher := NewSourceWatcher(container, a.Source, OnReconcileError(func(err error) {
+				container.log().Error("reconcile failed", "error", err)
+			}))
+			go watcher.Run(watchCtx)
+		}
+		if a.OnStarted != nil {
+			a.OnStarted(ctx, container)
+		}
+		if a.Shutdown.OnStarted != nil {
+			a.Shutdown.OnStarted()
+		}
+	}
+	opt.OnStage = func(stage string) {
+		stopping.Do(func() {
+			stopWatch()
+			if a.OnStopping != nil {
+				a.OnStopping(ctx, container)
+			}
+		})
+		if a.Shutdown.OnStage != nil {
+			a.Shutdown.OnStage(stage)
+		}
+	}
+	return RunUntilSignal(ctx, container, opt)
+}
+
+// 创建根容器并读取配置
+func (a *App) load(ctx context.Context) (container *ComponentContainer, configs []ComponentConfig, err error) {
+	if a.Source == nil {
+		if len(a.ConfigPaths) == 0 {
+			err = fmt.Errorf("%w, no config source, use -config to specify config files", ErrComponentConfigInvalid)
+			return
+		}
+		a.Source = NewFileConfigSource(a.ConfigPaths...)
+	}
+	if configs, err = a.Source.Load(ctx); err != nil {
+		err = fmt.Errorf("load configs: %w", err)
+		return
+	}
+	registry := a.Registry
+	if registry == nil {
+		registry = DefaultFactoryRegistry
+	}
+	opts := append([]Option{WithFactoryRegistry(registry)}, a.Options...)
+	if len(a.Profiles) > 0 {
+		opts = append(opts, WithProfiles(a.Profiles...))
+	}
+	container = NewComponentContainer(opts...).(*ComponentContainer)
+	return
+}
+
+// 输出加载计划
+func (a *App) printPlan(container *ComponentContainer, configs []ComponentConfig) (err error) {
+	plan, err := container.PlanReconcile(configs)
+	if err != nil {
+		return
+	}
+	types := make(map[ComponentName]ComponentConfig, len(configs))
+	for _, cfg := range configs {
+		types[cfg.Name] = cfg
+	}
+	fmt.Fprintf(a.Stdout, "%d components to load:\n", len(plan.Added))
+	for _, name := range plan.Added {
+		cfg := types[name]
+		if cfg.Refer != "" {
+			fmt.Fprintf(a.Stdout, "  + %s -> %s\n", name, cfg.Refer)
+			continue
+		}
+		fmt.Fprintf(a.Stdout, "  + %s (%s)\n", name, cfg.Type)
+	}
+	for _, name := range plan.Skipped {
+		fmt.Fprintf(a.Stdout, "  - %s (skipped)\n", name)
+	}
+	return
+}
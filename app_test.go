@@ -0,0 +1,79 @@
+package compcont
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeAppConfig(t *testing.T) string {
+	path := filepath.Join(t.TempDir(), "app.yaml")
+	err := os.WriteFile(path, []byte(`
+- name: db
+  type: svc
+- name: api
+  type: svc
+  deps: [db]
+- name: debug
+  type: svc
+  deps: [api]
+  when: profile == dev
+`), 0o644)
+	assert.NoError(t, err)
+	return path
+}
+
+func TestAppRun(t *testing.T) {
+	var log []string
+	registry := NewFactoryRegistry()
+	MustRegister(registry, newLifecycleFactory(&log))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var stderr bytes.Buffer
+	app := &App{Name: "test", Registry: registry, Stderr: &stderr}
+	app.OnStarted = func(ctx context.Context, container IComponentContainer) {
+		log = append(log, "started")
+		cancel()
+	}
+	app.OnStopping = func(ctx context.Context, container IComponentContainer) {
+		log = append(log, "stopping")
+	}
+	code := app.RunContext(ctx, []string{"-config", writeAppConfig(t), "-profile", "dev"})
+	assert.Equal(t, ExitOK, code, stderr.String())
+	assert.Equal(t, []string{"start db", "start api", "start debug", "started", "stopping", "stop debug", "stop api", "stop db"}, log)
+
+	// 未指定配置时返回失败
+	code = (&App{Registry: registry, Stderr: &stderr}).RunContext(context.Background(), nil)
+	assert.Equal(t, ExitShutdownFailed, code)
+	assert.Contains(t, stderr.String(), "no config source")
+}
+
+func TestAppDryRun(t *testing.T) {
+	var log []string
+	registry := NewFactoryRegistry()
+	MustRegister(registry, newLifecycleFactory(&log))
+
+	var stdout bytes.Buffer
+	app := &App{Registry: registry, Stdout: &stdout}
+	code := app.RunContext(context.Background(), []string{"-config=" + writeAppConfig(t), "-dry-run"})
+	assert.Equal(t, ExitOK, code)
+	assert.Empty(t, log)
+	assert.Equal(t, "2 components to load:\n  + db (svc)\n  + api (svc)\n  - debug (skipped)\n", stdout.String())
+
+	// 命令行参数替换预设的值，且不会修改App，再次运行时重新读取配置
+	stdout.Reset()
+	app.Profiles = []string{"test"}
+	app.ConfigPaths = []string{"missing.yaml"}
+	code = app.RunContext(context.Background(), []string{"-config=" + writeAppConfig(t), "-profile=dev", "-dry-run"})
+	assert.Equal(t, ExitOK, code)
+	assert.Equal(t, "3 components to load:\n  + db (svc)\n  + api (svc)\n  + debug (svc)\n", stdout.String())
+	assert.Equal(t, []string{"test"}, app.Profiles)
+	assert.Equal(t, []string{"missing.yaml"}, app.ConfigPaths)
+	assert.Nil(t, app.Source)
+	assert.False(t, app.DryRun)
+}
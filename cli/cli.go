@@ -0,0 +1,270 @@
+// Package cli 实现compcont命令行工具，在不创建组件实例的情况下校验、规划与可视化组件配置文件。
+// 工具需要链接注册了组件工厂的包才能识别组件类型，通常的用法是在项目中放置一个main包：
+//
+//	package main
+//
+//	import (
+//		"os"
+//
+//		compcont "github.com/go-compcont/compcont-core"
+//		"github.com/go-compcont/compcont-core/cli"
+//		_ "example.com/project/components" // 在init中向DefaultFactoryRegistry注册组件工厂
+//	)
+//
+//	func main() {
+//		os.Exit(cli.Main(compcont.DefaultFactoryRegistry, os.Args[1:], os.Stdout, os.Stderr))
+//	}
+//
+// 没有这样的main包时，validate命令可通过-allow-unknown-types跳过未注册类型的类型与配置校验，只校验名称、依赖与条件等结构
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"strings"
+
+	compcont "github.com/go-compcont/compcont-core"
+)
+
+// 退出码
+const (
+	ExitOK     = 0 // 成功
+	ExitFailed = 1 // 配置校验未通过或执行出错
+	ExitUsage  = 2 // 命令行参数错误
+)
+
+const usage = `usage: %s <command> [flags] <config files or directories...>
+
+commands:
+  validate   check component names, types, configs and dependencies,
+             -allow-unknown-types to skip types this binary does not register
+  plan       print the build order and dependency edges
+  graph      print the dependency graph, -format json, dot or mermaid
+  types      list registered component types
+  schema     print the JSON Schema of config files, -type for a single component config
+
+All output is JSON unless another format is requested, failures exit with status 1.
+`
+
+type command struct {
+	registry     compcont.IFactoryRegistry
+	stdout       io.Writer
+	flags        *flag.FlagSet
+	profiles     string
+	format       string
+	typeID       string
+	schema       bool
+	unknownTypes bool
+}
+
+// Main 执行命令行工具，args不包含程序名，返回值为进程应使用的退出码
+func Main(registry compcont.IFactoryRegistry, args []string, stdout, stderr io.Writer) int {
+	name := "compcont"
+	if len(args) == 0 || args[0] == "-h" || args[0] == "-help" || args[0] == "help" {
+		fmt.Fprintf(stderr, usage, name)
+		if len(args) == 0 {
+			return ExitUsage
+		}
+		return ExitOK
+	}
+
+	cmd := &command{registry: registry, stdout: stdout, flags: flag.NewFlagSet(name+" "+args[0], flag.ContinueOnError)}
+	cmd.flags.SetOutput(stderr)
+	var run func() error
+	switch args[0] {
+	case "validate":
+		cmd.flags.StringVar(&cmd.profiles, "profile", "", "active profiles, comma separated")
+		cmd.flags.BoolVar(&cmd.unknownTypes, "allow-unknown-types", false, "skip type and config checks of unregistered component types")
+		run = cmd.validate
+	case "plan":
+		cmd.flags.StringVar(&cmd.profiles, "profile", "", "active profiles, comma separated")
+		run = cmd.plan
+	case "graph":
+		cmd.flags.StringVar(&cmd.profiles, "profile", "", "active profiles, comma separated")
+		cmd.flags.StringVar(&cmd.format, "format", "json", "output format: json, dot or mermaid")
+		run = cmd.graph
+	case "types":
+		cmd.flags.BoolVar(&cmd.schema, "schema", false, "include the JSON Schema of each component config")
+		run = cmd.types
+	case "schema":
+		cmd.flags.StringVar(&cmd.typeID, "type", "", "print the config schema of a single component type")
+		run = cmd.configSchema
+	default:
+		fmt.Fprintf(stderr, "unknown command %q\n", args[0])
+		fmt.Fprintf(stderr, usage, name)
+		return ExitUsage
+	}
+	if err := cmd.flags.Parse(args[1:]); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return ExitOK
+		}
+		return ExitUsage
+	}
+
+	err := run()
+	var failed validationFailed
+	switch {
+	case errors.As(err, &failed):
+		return ExitFailed
+	case err != nil:
+		cmd.writeJSON(map[string]string{"error": err.Error()})
+		return ExitFailed
+	}
+	return ExitOK
+}
+
+// 配置校验未通过，结果已经输出
+type validationFailed struct{}
+
+func (validationFailed) Error() string { return "validation failed" }
+
+// 以JSON格式输出
+func (c *command) writeJSON(v any) error {
+	encoder := json.NewEncoder(c.stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
+
+// 加载命令行参数中的配置文件，并创建用于校验与规划的空容器
+func (c *command) load() (container *compcont.ComponentContainer, configs []compcont.ComponentConfig, err error) {
+	if c.flags.NArg() == 0 {
+		err = errors.New("no config files specified")
+		return
+	}
+	if configs, err = compcont.LoadConfigPaths(c.flags.Args()...); err != nil {
+		return
+	}
+	var profiles []string
+	for _, p := range strings.Split(c.profiles, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			profiles = append(profiles, p)
+		}
+	}
+	container = compcont.NewComponentContainer(
+		compcont.WithFactoryRegistry(c.registry),
+		compcont.WithProfiles(profiles...),
+	).(*compcont.ComponentContainer)
+	return
+}
+
+// 校验结果
+type validateResult struct {
+	Valid      bool                       `json:"valid"`
+	Components int                        `json:"components"`
+	Issues     []compcont.ValidationIssue `json:"issues"`
+}
+
+func (c *command) validate() (err error) {
+	container, configs, err := c.load()
+	if err != nil {
+		return
+	}
+	result := validateResult{Components: len(configs)}
+	if c.unknownTypes {
+		result.Issues = container.ValidateConfigs(configs, compcont.WithAllowUnknownTypes())
+	} else {
+		result.Issues = container.ValidateConfigs(configs)
+	}
+	result.Valid = len(result.Issues) == 0
+	if result.Issues == nil {
+		result.Issues = []compcont.ValidationIssue{}
+	}
+	if err = c.writeJSON(result); err == nil && !result.Valid {
+		err = validationFailed{}
+	}
+	return
+}
+
+// 加载计划
+type planResult struct {
+	Order   []compcont.ComponentName `json:"order"`   // 构建顺序
+	Skipped []compcont.ComponentName `json:"skipped"` // 因条件不满足而被跳过的组件
+	Edges   []compcont.GraphEdge     `json:"edges"`   // 由依赖与引用推断出的边
+}
+
+func (c *command) plan() (err error) {
+	container, configs, err := c.load()
+	if err != nil {
+		return
+	}
+	plan, err := container.PlanReconcile(configs)
+	if err != nil {
+		return
+	}
+	graph, err := container.PlanGraph(configs)
+	if err != nil {
+		return
+	}
+	result := planResult{Order: plan.Added, Skipped: plan.Skipped, Edges: graph.Edges}
+	if result.Order == nil {
+		result.Order = []compcont.ComponentName{}
+	}
+	if result.Skipped == nil {
+		result.Skipped = []compcont.ComponentName{}
+	}
+	if result.Edges == nil {
+		result.Edges = []compcont.GraphEdge{}
+	}
+	return c.writeJSON(result)
+}
+
+func (c *command) graph() (err error) {
+	container, configs, err := c.load()
+	if err != nil {
+		return
+	}
+	graph, err := container.PlanGraph(configs)
+	if err != nil {
+		return
+	}
+	switch c.format {
+	case "json":
+		return graph.WriteJSON(c.stdout)
+	case "dot":
+		return graph.WriteDOT(c.stdout)
+	case "mermaid":
+		return graph.WriteMermaid(c.stdout)
+	}
+	return fmt.Errorf("unsupported graph format %q", c.format)
+}
+
+func (c *command) types() (err error) {
+	infos, err := compcont.DescribeFactories(c.registry)
+	if err != nil {
+		return
+	}
+	if infos == nil {
+		infos = []compcont.FactoryInfo{}
+	}
+	if !c.schema {
+		for i := range infos {
+			infos[i].ConfigSchema = nil
+		}
+	}
+	return c.writeJSON(infos)
+}
+
+func (c *command) configSchema() (err error) {
+	if c.typeID == "" {
+		schema, err := compcont.ConfigFileSchema(c.registry)
+		if err != nil {
+			return err
+		}
+		return c.writeJSON(schema)
+	}
+	factory, err := c.registry.GetFactory(compcont.ComponentTypeID(c.typeID))
+	if err != nil {
+		return
+	}
+	info := compcont.DescribeFactory(factory)
+	if info.ConfigSchema == nil {
+		return fmt.Errorf("component type %s does not describe its config", c.typeID)
+	}
+	schema := map[string]any{"$schema": compcont.JSONSchemaDraft, "title": c.typeID}
+	maps.Copy(schema, info.ConfigSchema)
+	return c.writeJSON(schema)
+}
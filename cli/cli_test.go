@@ -0,0 +1,111 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	compcont "github.com/go-compcont/compcont-core"
+	"github.com/stretchr/testify/assert"
+)
+
+type serverConfig struct {
+	Addr string `ccf:"addr"`
+}
+
+func newRegistry() compcont.IFactoryRegistry {
+	registry := compcont.NewFactoryRegistry()
+	compcont.MustRegister(registry, &compcont.TypedSimpleComponentFactory[serverConfig, string]{TypeID: "server", Description: "http server"})
+	return registry
+}
+
+func writeConfig(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "components.yaml")
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	return path
+}
+
+func run(args ...string) (code int, stdout string) {
+	var out, errOut bytes.Buffer
+	code = Main(newRegistry(), args, &out, &errOut)
+	return code, out.String()
+}
+
+func TestValidate(t *testing.T) {
+	path := writeConfig(t, `
+- name: db
+  type: server
+- name: api
+  type: server
+  deps: [db, "cache?"]
+  config: {addr: ":80"}
+`)
+	code, out := run("validate", path)
+	assert.Equal(t, ExitOK, code)
+	assert.JSONEq(t, `{"valid": true, "components": 2, "issues": []}`, out)
+
+	code, out = run("plan", path)
+	assert.Equal(t, ExitOK, code)
+	assert.JSONEq(t, `{"order": ["db", "api"], "skipped": [], "edges": [{"from": "/api", "to": "/db", "kind": "dep"}]}`, out)
+
+	code, out = run("graph", "-format", "mermaid", path)
+	assert.Equal(t, ExitOK, code)
+	assert.Contains(t, out, "n0 -->|dep| n1")
+
+	path = writeConfig(t, `
+- name: api
+  type: server
+  deps: [db]
+  config: {port: 80}
+`)
+	code, out = run("validate", path)
+	assert.Equal(t, ExitFailed, code)
+	var result validateResult
+	assert.NoError(t, json.Unmarshal([]byte(out), &result))
+	assert.False(t, result.Valid)
+	assert.Len(t, result.Issues, 2)
+
+	code, out = run("plan", path)
+	assert.Equal(t, ExitFailed, code)
+	assert.Contains(t, out, `"error"`)
+
+	// 跳过未注册类型的校验，其余问题照常报告
+	path = writeConfig(t, `
+- name: cache
+  type: redis
+  config: {addr: ":6379"}
+- name: api
+  type: server
+  deps: [cache, db]
+`)
+	code, out = run("validate", path)
+	assert.Equal(t, ExitFailed, code)
+	assert.Contains(t, out, "type not registered")
+	code, out = run("validate", "-allow-unknown-types", path)
+	assert.Equal(t, ExitFailed, code)
+	assert.NoError(t, json.Unmarshal([]byte(out), &result))
+	assert.Equal(t, []compcont.ValidationIssue{{Name: "api", Field: "deps", Message: "dependency db not found"}}, result.Issues)
+	code, _ = run("unknown")
+	assert.Equal(t, ExitUsage, code)
+}
+
+func TestTypesAndSchema(t *testing.T) {
+	code, out := run("types")
+	assert.Equal(t, ExitOK, code)
+	assert.JSONEq(t, `[{"type": "server", "description": "http server", "config_type": "cli.serverConfig", "instance_type": "string"}]`, out)
+
+	code, out = run("schema", "-type", "server")
+	assert.Equal(t, ExitOK, code)
+	assert.JSONEq(t, `{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"title": "server",
+		"type": "object",
+		"properties": {"addr": {"type": "string"}}
+	}`, out)
+
+	code, out = run("schema")
+	assert.Equal(t, ExitOK, code)
+	assert.Contains(t, out, `"enum": [`)
+}
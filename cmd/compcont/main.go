@@ -0,0 +1,15 @@
+// compcont命令行工具，只能识别向DefaultFactoryRegistry注册的组件类型。
+// 不关心组件配置时，validate命令通过-allow-unknown-types跳过未注册类型的校验；
+// 校验自定义组件的配置时，参考cli包的文档在项目中放置一个匿名导入组件包的main包
+package main
+
+import (
+	"os"
+
+	compcont "github.com/go-compcont/compcont-core"
+	"github.com/go-compcont/compcont-core/cli"
+)
+
+func main() {
+	os.Exit(cli.Main(compcont.DefaultFactoryRegistry, os.Args[1:], os.Stdout, os.Stderr))
+}
@@ -1,8 +1,12 @@
 package compcont
 
 import (
+	"log/slog"
+	"reflect"
 	"regexp"
 	"slices"
+	"strings"
+	"sync"
 )
 
 type ComponentTypeID string
@@ -23,25 +27,101 @@ func (n ComponentName) String() string {
 	return string(n)
 }
 
+// 依赖名称的可选标记，如deps: [tracer?]，被依赖的组件存在时参与构建排序，不存在或被禁用时忽略该依赖
+const optionalDepSuffix = "?"
+
+// 解析依赖名称，返回被依赖的组件名称以及是否为可选依赖
+func parseDep(dep ComponentName) (name ComponentName, optional bool) {
+	n, optional := strings.CutSuffix(string(dep), optionalDepSuffix)
+	return ComponentName(n), optional
+}
+
 type ComponentConfig struct {
-	Name   ComponentName   `json:"name" yaml:"name"`     // 组件名称，不填为空值，即匿名组件
-	Type   ComponentTypeID `json:"type" yaml:"type"`     // 组件类型
-	Refer  string          `json:"refer" yaml:"refer"`   // 来自其他组件的引用
-	Deps   []ComponentName `json:"deps" yaml:"deps"`     // 构造该组件需要依赖的其他组件名称
-	Config any             `json:"config" yaml:"config"` // 组件的自身配置
+	Name    ComponentName     `json:"name" yaml:"name"`       // 组件名称，不填为空值，即匿名组件
+	Type    ComponentTypeID   `json:"type" yaml:"type"`       // 组件类型
+	Refer   string            `json:"refer" yaml:"refer"`     // 来自其他组件的引用
+	Deps    []ComponentName   `json:"deps" yaml:"deps"`       // 构造该组件需要依赖的其他组件名称，带有?后缀的为可选依赖
+	Config  any               `json:"config" yaml:"config"`   // 组件的自身配置
+	Enabled *bool             `json:"enabled" yaml:"enabled"` // 是否启用，不填为启用
+	When    string            `json:"when" yaml:"when"`       // 启用条件表达式，基于环境变量与profile求值
+	Labels  map[string]string `json:"labels" yaml:"labels"`   // 组件标签，用于按标签选择器查询一组组件
+	Scope   ComponentScope    `json:"scope" yaml:"scope"`     // 组件作用域，不填为单例
+	Lazy    bool              `json:"lazy" yaml:"lazy"`       // 是否懒加载，懒加载的单例组件在首次获取时才创建实例
+	Health  HealthConfig      `json:"health" yaml:"health"`   // 健康检查配置
+	Retry   RetryConfig       `json:"retry" yaml:"retry"`     // 创建失败时的重试配置
 }
 
 // 运行时的组件的结构
 type Component struct {
 	BuildContext BuildContext // 运行时一个组件必然存在一个Context，且不可变，这里使用值类型
 	Instance     any
+	lazy         *lazyComponent // 懒加载组件的状态，非懒加载组件为nil
 }
 
 // 构造组件时使用的上下文环境结构
 type BuildContext struct {
-	Container IComponentContainer // 当前组件所在容器
-	Config    ComponentConfig     // 组件配置
-	Mount     *Component          // 组件实例有可能不存在
+	Container   IComponentContainer // 当前组件所在容器
+	Config      ComponentConfig     // 组件配置
+	Mount       *Component          // 组件实例有可能不存在
+	MissingDeps []ComponentName     // 构造时不存在的可选依赖，工厂可据此选择降级方案
+	owned       *ownedComponents    // 构造时加载的匿名组件
+	logger      *slog.Logger        // 组件专属的日志记录器
+	owner       string              // 匿名组件所属组件的绝对路径，未归属任何组件时为所在容器的路径
+	build       *buildContext       // 构造期间的context，构造结束后清空
+	span        ISpan               // 组件创建的span，用于关联依赖组件
+}
+
+// 组件构造时加载的匿名组件，随组件一同销毁
+type ownedComponents struct {
+	mu         sync.Mutex
+	components []Component
+}
+
+// 加载一个归属于当前组件的匿名组件，当前组件销毁后该匿名组件会被自动销毁
+func (c BuildContext) LoadAnonymousComponent(config ComponentConfig) (component Component, err error) {
+	if container, ok := c.Container.(*ComponentContainer); ok {
+		component, err = container.loadComponent(c.Context(), formatPath(c.GetAbsolutePath()), config)
+	} else {
+		component, err = c.Container.LoadAnonymousComponent(config)
+	}
+	if err != nil {
+		return
+	}
+	c.own(component)
+	return
+}
+
+// 将组件记录为当前组件所有，随当前组件一同销毁
+func (c BuildContext) own(component Component) {
+	if c.owned == nil {
+		return
+	}
+	c.owned.mu.Lock()
+	c.owned.components = append(c.owned.components, component)
+	c.owned.mu.Unlock()
+}
+
+// 获取构造当前组件时加载的匿名组件，按加载顺序排列
+func (c BuildContext) OwnedComponents() []Component {
+	if c.owned == nil {
+		return nil
+	}
+	c.owned.mu.Lock()
+	defer c.owned.mu.Unlock()
+	return slices.Clone(c.owned.components)
+}
+
+// 判断构造组件时某个依赖是否存在，可选依赖不存在时返回false
+func (c BuildContext) HasDep(name ComponentName) bool {
+	if slices.Contains(c.MissingDeps, name) {
+		return false
+	}
+	for _, dep := range c.Config.Deps {
+		if n, _ := parseDep(dep); n == name {
+			return true
+		}
+	}
+	return false
 }
 
 func (c BuildContext) FindRoot() BuildContext {
@@ -82,3 +162,8 @@ type IComponentFactory interface {
 	CreateInstance(ctx BuildContext, config any) (instance any, err error)
 	DestroyInstance(ctx BuildContext, instance any) (err error) // 组件销毁器
 }
+
+// 组件工厂可实现该接口声明组件实例的类型，用于在不创建实例的情况下按类型查找组件
+type IInstanceTyped interface {
+	InstanceType() reflect.Type
+}
@@ -0,0 +1,225 @@
+package compcont
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// 条件表达式的求值环境
+type conditionEnv struct {
+	lookupEnv func(key string) (string, bool)
+	profiles  []string
+}
+
+// 判断组件配置在当前环境下是否启用
+//
+// when表达式支持如下语法：
+//
+//	env.TRACING_ENABLED             环境变量存在且不为空、0、false、no、off
+//	env.REGION == "cn"              环境变量等于指定值，同理支持!=
+//	profile == prod                 当前容器启用了指定的profile，同理支持!=
+//	!expr、expr && expr、expr || expr、(expr)、true、false
+func (e conditionEnv) enabled(cfg ComponentConfig) (enabled bool, err error) {
+	if cfg.Enabled != nil && !*cfg.Enabled {
+		return false, nil
+	}
+	if strings.TrimSpace(cfg.When) == "" {
+		return true, nil
+	}
+	p := &conditionParser{env: e}
+	if p.tokens, err = tokenizeCondition(cfg.When); err == nil {
+		enabled, err = p.parseOr()
+	}
+	if err == nil && p.pos < len(p.tokens) {
+		err = fmt.Errorf("unexpected token %q", p.tokens[p.pos])
+	}
+	if err != nil {
+		err = fmt.Errorf("%w, invalid when expression %q of component %s: %w", ErrComponentConfigInvalid, cfg.When, cfg.Name, err)
+	}
+	return
+}
+
+// 过滤掉被禁用的组件，并检查对被禁用组件的依赖，返回被跳过的组件
+func (e conditionEnv) filter(configMap map[ComponentName]ComponentConfig) (skipped []ComponentName, err error) {
+	disabled := make(set[ComponentName])
+	for name, cfg := range configMap {
+		var enabled bool
+		if enabled, err = e.enabled(cfg); err != nil {
+			return
+		}
+		if !enabled {
+			disabled[name] = struct{}{}
+			skipped = append(skipped, name)
+			delete(configMap, name)
+		}
+	}
+	slices.Sort(skipped)
+
+	// 对被禁用组件的可选依赖会在构建时被忽略
+	for name, cfg := range configMap {
+		for _, dep := range cfg.Deps {
+			target, optional := parseDep(dep)
+			if _, ok := disabled[target]; ok && !optional {
+				err = fmt.Errorf("%w, component %s depends on disabled component %s", ErrComponentDisabled, name, target)
+				return
+			}
+		}
+	}
+	return
+}
+
+// 将条件表达式切分为token
+func tokenizeCondition(expr string) (tokens []string, err error) {
+	for i := 0; i < len(expr); {
+		ch := rune(expr[i])
+		switch {
+		case unicode.IsSpace(ch):
+			i++
+		case strings.HasPrefix(expr[i:], "&&"), strings.HasPrefix(expr[i:], "||"),
+			strings.HasPrefix(expr[i:], "=="), strings.HasPrefix(expr[i:], "!="):
+			tokens = append(tokens, expr[i:i+2])
+			i += 2
+		case ch == '!' || ch == '(' || ch == ')':
+			tokens = append(tokens, string(ch))
+			i++
+		case ch == '"' || ch == '\'':
+			end := strings.IndexRune(expr[i+1:], ch)
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated string at %d", i)
+			}
+			tokens = append(tokens, expr[i:i+end+2])
+			i += end + 2
+		default:
+			start := i
+			for i < len(expr) && !strings.ContainsRune(" \t\r\n!()&|=\"'", rune(expr[i])) {
+				i++
+			}
+			if start == i {
+				return nil, fmt.Errorf("unexpected character %q at %d", ch, i)
+			}
+			tokens = append(tokens, expr[start:i])
+		}
+	}
+	return
+}
+
+// 条件表达式的递归下降解析器，解析的同时完成求值
+type conditionParser struct {
+	env    conditionEnv
+	tokens []string
+	pos    int
+}
+
+func (p *conditionParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *conditionParser) next() (token string, err error) {
+	if p.pos >= len(p.tokens) {
+		return "", fmt.Errorf("unexpected end of expression")
+	}
+	token = p.tokens[p.pos]
+	p.pos++
+	return
+}
+
+func (p *conditionParser) parseOr() (value bool, err error) {
+	if value, err = p.parseAnd(); err != nil {
+		return
+	}
+	for p.peek() == "||" {
+		p.pos++
+		var rhs bool
+		if rhs, err = p.parseAnd(); err != nil {
+			return
+		}
+		value = value || rhs
+	}
+	return
+}
+
+func (p *conditionParser) parseAnd() (value bool, err error) {
+	if value, err = p.parseUnary(); err != nil {
+		return
+	}
+	for p.peek() == "&&" {
+		p.pos++
+		var rhs bool
+		if rhs, err = p.parseUnary(); err != nil {
+			return
+		}
+		value = value && rhs
+	}
+	return
+}
+
+func (p *conditionParser) parseUnary() (value bool, err error) {
+	token, err := p.next()
+	if err != nil {
+		return
+	}
+	switch token {
+	case "!":
+		value, err = p.parseUnary()
+		return !value, err
+	case "(":
+		if value, err = p.parseOr(); err != nil {
+			return
+		}
+		if token, err = p.next(); err == nil && token != ")" {
+			err = fmt.Errorf("expected ) but got %q", token)
+		}
+		return
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	}
+
+	// 比较表达式或环境变量的真值判断
+	var actual string
+	var isProfile bool
+	switch {
+	case token == "profile":
+		isProfile = true
+	case strings.HasPrefix(token, "env."):
+		actual, _ = p.env.lookupEnv(strings.TrimPrefix(token, "env."))
+	default:
+		err = fmt.Errorf("unknown identifier %q", token)
+		return
+	}
+	op := p.peek()
+	if op != "==" && op != "!=" {
+		if isProfile {
+			err = fmt.Errorf("profile must be compared with == or !=")
+			return
+		}
+		switch strings.ToLower(actual) {
+		case "", "0", "false", "no", "off":
+			return false, nil
+		}
+		return true, nil
+	}
+	p.pos++
+	expected, err := p.next()
+	if err != nil {
+		return
+	}
+	if len(expected) >= 2 && (expected[0] == '"' || expected[0] == '\'') {
+		expected = expected[1 : len(expected)-1]
+	}
+	if isProfile {
+		value = slices.Contains(p.env.profiles, expected)
+	} else {
+		value = actual == expected
+	}
+	if op == "!=" {
+		value = !value
+	}
+	return
+}
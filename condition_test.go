@@ -0,0 +1,74 @@
+package compcont
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConditionEnabled(t *testing.T) {
+	env := conditionEnv{
+		lookupEnv: func(key string) (string, bool) {
+			v, ok := map[string]string{"TRACING_ENABLED": "1", "DEBUG": "false", "REGION": "cn"}[key]
+			return v, ok
+		},
+		profiles: []string{"prod"},
+	}
+	disabled := false
+	for _, c := range []struct {
+		cfg      ComponentConfig
+		expected bool
+	}{
+		{ComponentConfig{}, true},
+		{ComponentConfig{Enabled: &disabled}, false},
+		{ComponentConfig{When: "env.TRACING_ENABLED"}, true},
+		{ComponentConfig{When: "env.DEBUG || env.MISSING"}, false},
+		{ComponentConfig{When: `env.REGION == "cn" && profile == prod`}, true},
+		{ComponentConfig{When: "!(profile == dev) && profile != 'test'"}, true},
+	} {
+		enabled, err := env.enabled(c.cfg)
+		assert.NoError(t, err, c.cfg.When)
+		assert.Equal(t, c.expected, enabled, c.cfg.When)
+	}
+
+	for _, when := range []string{"env.A &&", "profile", "unknown", "(true", "env.A = 1"} {
+		_, err := env.enabled(ComponentConfig{When: when})
+		assert.ErrorIs(t, err, ErrComponentConfigInvalid, when)
+	}
+}
+
+func TestConditionalLoading(t *testing.T) {
+	registry := NewFactoryRegistry()
+	MustRegister(registry, newReconcileFactory(&reconcileRecorder{}))
+	container := NewComponentContainer(WithFactoryRegistry(registry), WithProfiles("prod")).(*ComponentContainer)
+
+	configs := []ComponentConfig{
+		{Name: "tracer", Type: "reconcile", When: "profile == dev"},
+		{Name: "server", Type: "reconcile", Deps: []ComponentName{"tracer?"}},
+	}
+	plan, err := container.PlanReconcile(configs)
+	assert.NoError(t, err)
+	assert.Equal(t, []ComponentName{"tracer"}, plan.Skipped)
+	assert.Equal(t, []ComponentName{"server"}, plan.Added)
+	assert.NoError(t, container.ApplyReconcile(plan))
+
+	configs[1].Deps = []ComponentName{"tracer"}
+	_, err = container.PlanReconcile(configs)
+	assert.ErrorIs(t, err, ErrComponentDisabled)
+}
+
+func TestConditionalAnonymousLoading(t *testing.T) {
+	registry := NewFactoryRegistry()
+	MustRegister(registry, newReconcileFactory(&reconcileRecorder{}))
+	container := NewComponentContainer(WithFactoryRegistry(registry), WithProfiles("prod"))
+
+	_, err := container.LoadAnonymousComponent(ComponentConfig{Type: "reconcile", When: "profile == dev"})
+	assert.ErrorIs(t, err, ErrComponentDisabled)
+	disabled := false
+	_, err = container.LoadAnonymousComponent(ComponentConfig{Type: "reconcile", Enabled: &disabled})
+	assert.ErrorIs(t, err, ErrComponentDisabled)
+	_, err = container.LoadAnonymousComponent(ComponentConfig{Type: "reconcile", When: "profile =="})
+	assert.ErrorIs(t, err, ErrComponentConfigInvalid)
+	_, err = container.LoadAnonymousComponent(ComponentConfig{Type: "reconcile", When: "profile == prod"})
+	assert.NoError(t, err)
+}
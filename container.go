@@ -1,5 +1,10 @@
 package compcont
 
+// 匿名组件的加载器，IComponentContainer与BuildContext均实现了该接口
+type IComponentLoader interface {
+	LoadAnonymousComponent(config ComponentConfig) (component Component, err error)
+}
+
 // 组件的容器抽象
 type IComponentContainer interface {
 	GetContext() BuildContext                                                       // 当容器自身作为组件时的组件上下文对象
@@ -12,3 +17,24 @@ type IComponentContainer interface {
 	PutComponent(name ComponentName, component Component) (err error)               // 直接放入一个组件
 	GetParent() IComponentContainer                                                 // 如果是根容器，则返回nil
 }
+
+// 容器可实现该接口以支持按标签查找组件，ComponentContainer实现了该接口
+type IComponentLister interface {
+	ListComponents(selector Selector) (components []Component) // 按构建顺序获取标签满足选择器的所有具名单例组件
+}
+
+// 容器可实现该接口以在不创建实例的情况下查看组件的状态，ComponentContainer实现了该接口
+type IComponentInspector interface {
+	InspectComponent(name ComponentName) (info ComponentInfo, err error) // 获取一个具名组件的状态信息，不会触发懒加载组件的创建
+}
+
+// 容器可实现该接口以支持热更新，ComponentContainer实现了该接口
+type IReconciler interface {
+	PlanReconcile(configs []ComponentConfig) (plan ReconcilePlan, err error) // 对比新配置与已加载组件，计算热更新的变更计划
+	ApplyReconcile(plan ReconcilePlan) error                                 // 执行热更新计划，失败时回滚
+}
+
+// 容器可实现该接口以支持订阅组件的生命周期事件，ComponentContainer实现了该接口
+type IEventSource interface {
+	Subscribe(handler EventHandler) (unsubscribe func()) // 订阅容器及其子容器中组件的生命周期事件
+}
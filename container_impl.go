@@ -1,9 +1,16 @@
 package compcont
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"strings"
+	"log/slog"
+	"maps"
+	"os"
+	"slices"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 type ComponentContainer struct {
@@ -11,7 +18,26 @@ type ComponentContainer struct {
 	parent          IComponentContainer
 	factoryRegistry IFactoryRegistry
 	components      map[ComponentName]Component
+	configs         map[ComponentName]ComponentConfig // 通过LoadNamedComponents加载的具名组件的声明配置
+	profiles        []string                          // 当前容器启用的profile，用于组件的条件加载
 	mu              sync.RWMutex
+	reconcileMu     sync.Mutex          // 串行化热更新操作
+	scopeOf         *ComponentContainer // 作用域容器所属的容器，非作用域容器为nil
+	scopedNames     []ComponentName     // 作用域内已创建的scoped组件，按创建顺序排列
+	events          *eventLog           // 最近的生命周期事件，与父容器共享
+	dispatcher      *eventDispatcher    // 事件分发器，与父容器共享
+	hooks           eventHooks          // 生命周期事件的订阅者
+	lifecycleMu     sync.Mutex          // 串行化启动与停止操作
+	started         []ComponentName     // 已启动的组件，按启动顺序排列
+	running         atomic.Bool         // 容器是否已启动
+	logger          *slog.Logger
+	parallelism     int              // 并行加载组件的最大并发数，不大于1时按拓扑顺序串行加载
+	profiler        *profiler        // 组件各阶段的耗时记录，与父容器共享
+	metrics         *metricsRecorder // 指标上报，未设置时为nil
+	tracer          ITracer
+	supervisor      *supervisor  // 组件的监督者，未设置时为nil
+	retryableErrors []error      // 可重试的错误
+	constructing    atomic.Int32 // 正在构造的组件数量
 }
 
 // GetSelfComponentName implements IComponentContainer.
@@ -27,12 +53,24 @@ func (c *ComponentContainer) GetParent() IComponentContainer {
 // GetComponentMetadata implements IComponentContainer.
 func (c *ComponentContainer) GetComponent(name ComponentName) (component Component, err error) {
 	c.mu.RLock()
-	defer c.mu.RUnlock()
 	inner, ok := c.components[name]
+	cfg := c.configs[name]
+	c.mu.RUnlock()
 	if !ok {
+		// 作用域容器中不存在的组件按其作用域从所属容器中获取
+		if c.scopeOf != nil {
+			cfg, _ = c.scopeOf.declaredConfig(name)
+			return c.getScopedComponent(name, cfg)
+		}
 		err = fmt.Errorf("%w, name: %s", ErrComponentNameNotFound, name)
 		return
 	}
+	if !cfg.Scope.isSingleton() {
+		return c.getScopedComponent(name, cfg)
+	}
+	if inner.lazy != nil {
+		return c.getLazyComponent(inner)
+	}
 	component = inner
 	return
 }
@@ -43,59 +81,78 @@ func (c *ComponentContainer) FactoryRegistry() IFactoryRegistry {
 }
 
 func (c *ComponentContainer) MustLoadComponent(config ComponentConfig) (component Component, err error) {
+	return c.loadComponent(c.context.Context(), "", config)
+}
+
+// 加载一个组件，组件创建的span是parent中span的子span，owner为匿名组件所属组件的绝对路径
+func (c *ComponentContainer) loadComponent(parent context.Context, owner string, config ComponentConfig) (component Component, err error) {
 	if config.Type == "" {
 		if config.Refer == "" { // 引用组件
 			err = fmt.Errorf("%w, type && refer are empty, componentName: %s, componentType: %s, refer: %s", ErrComponentConfigInvalid, config.Name, config.Type, config.Refer)
 			return
 		}
-		parts := strings.Split(config.Refer, "/")
-		absolute := false
-		if parts[0] == "" { // 绝对路径
-			absolute = true
-			parts = parts[1:]
-		}
-
-		var findPath []ComponentName
-		for _, p := range parts {
-
-			if n := ComponentName(p); p != "." && p != ".." && !n.Validate() {
-				err = fmt.Errorf("%w, in refer %s", ErrComponentNameInvalid, config.Refer)
-				return
-			} else {
-				findPath = append(findPath, n)
-			}
-		}
-
-		// 寻找到要引用的树节点，再从对应节点上获取组件
-		var ctx BuildContext
-		ctx, err = find(c, findPath, absolute)
-		if err != nil {
-			return
-		}
-		return ctx.Container.GetComponent(ctx.Config.Name)
+		return Resolve(c, config.Refer)
 	}
-	// 检查依赖关系是否满足
+	// 检查依赖关系是否满足，缺失的可选依赖记录到上下文中
+	var missingDeps []ComponentName
 	for _, dep := range config.Deps {
-		if _, ok := c.components[dep]; !ok {
-			err = fmt.Errorf("%w, dependency %s not found", ErrComponentDependencyNotFound, dep)
-			return
+		name, optional := parseDep(dep)
+		if c.hasComponent(name) {
+			continue
+		}
+		if optional {
+			missingDeps = append(missingDeps, name)
+			continue
 		}
+		err = fmt.Errorf("%w, dependency %s not found", ErrComponentDependencyNotFound, dep)
+		return
 	}
 
 	// 获取工厂
-	factory, err := c.factoryRegistry.GetFactory(config.Type)
+	factory, err := c.getFactory(config.Type)
 	if err != nil {
 		return
 	}
 
 	ctx := BuildContext{
-		Config:    config,
-		Container: c,
+		Config:      config,
+		Container:   c,
+		MissingDeps: missingDeps,
+		owned:       &ownedComponents{},
+		owner:       owner,
 	}
+	if config.Name == "" && owner == "" {
+		if ctx.owner = containerPath(c); ctx.owner == "" {
+			ctx.owner = "/"
+		}
+	}
+	ctx.logger = c.logger.With("path", formatPath(ctx.GetAbsolutePath()), "type", config.Type)
+	spanOpt := componentSpanOptions(ctx)
+	spanOpt.Links = c.depSpans(config)
+	spanCtx, span := c.tracer.StartSpan(parent, SpanCreate, spanOpt)
+	ctx.build, ctx.span = &buildContext{ctx: spanCtx}, span
+	defer func() { span.End(err) }()
+
+	// 通知订阅者，订阅者可否决创建或调整传给工厂的配置
+	before := Event{Type: EventBeforeCreate, Time: time.Now(), Context: ctx}
+	if err = c.emit(&before); err != nil {
+		c.emit(&Event{Type: EventCreateFailed, Time: time.Now(), Context: ctx, Err: err})
+		return
+	}
+	ctx.Config.Config = before.Context.Config.Config
 
 	// 构造组件实例
-	instance, err := factory.CreateInstance(ctx, config.Config)
+	start := time.Now()
+	if config.Name != "" && config.Scope.isSingleton() { // 非单例组件的实例按需创建，不计入启动耗时
+		c.profiler.begin(formatPath(ctx.GetAbsolutePath()), config.Type, c.depPaths(config, missingDeps))
+	}
+	c.constructing.Add(1)
+	instance, attempts, err := c.createInstance(factory, ctx)
+	c.constructing.Add(-1)
+	ctx.build.end()
+	ctx.recordPhase(PhaseCreate, start, time.Since(start))
 	if err != nil {
+		c.emit(&Event{Type: EventCreateFailed, Time: time.Now(), Context: ctx, Duration: time.Since(start), Err: err, Attempt: attempts})
 		return
 	}
 
@@ -103,11 +160,25 @@ func (c *ComponentContainer) MustLoadComponent(config ComponentConfig) (componen
 	component = Component{Instance: instance}
 	ctx.Mount = &component
 	component.BuildContext = ctx
+	c.emit(&Event{Type: EventAfterCreate, Time: time.Now(), Context: ctx, Duration: time.Since(start), Attempt: attempts})
 	return
 }
 
-// LoadAnonymousComponent 加载一个匿名组件，返回该组件实例，生命周期不由Registry控制，需要由该方法的调用方自行处理
+// LoadAnonymousComponent 加载一个匿名组件，返回该组件实例，生命周期不由Registry控制，需要由该方法的调用方自行处理。
+// 在工厂中通过BuildContext.LoadAnonymousComponent加载的匿名组件归属于正在构造的组件，随其一同销毁。
+// 配置的enabled与when同样生效，被禁用时返回ErrComponentDisabled
 func (c *ComponentContainer) LoadAnonymousComponent(config ComponentConfig) (component Component, err error) {
+	enabled, err := c.conditionEnv().enabled(config)
+	if err != nil {
+		return
+	}
+	if !enabled {
+		err = fmt.Errorf("%w, anonymous component of type %s", ErrComponentDisabled, config.Type)
+		return
+	}
+	if c.constructing.Load() > 0 { // 很可能是工厂误用了ctx.Container，加载的组件不会随所属组件销毁
+		c.log().Warn("anonymous component loaded through the container during component construction is not owned by any component, use BuildContext.LoadAnonymousComponent instead", "type", config.Type)
+	}
 	return c.MustLoadComponent(config)
 }
 
@@ -121,29 +192,46 @@ func (c *ComponentContainer) PutComponent(name ComponentName, component Componen
 
 // LoadNamedComponents 加载一批具名组件，内部会自行根据拓扑排序顺序加载组件
 func (c *ComponentContainer) LoadNamedComponents(configs []ComponentConfig) (err error) {
+	return c.LoadNamedComponentsContext(c.context.Context(), configs)
+}
+
+// LoadNamedComponentsContext 与LoadNamedComponents相同，ctx会传递给组件的BuildContext，取消时中止组件创建的重试等待
+func (c *ComponentContainer) LoadNamedComponentsContext(ctx context.Context, configs []ComponentConfig) (err error) {
+	ctx, span := c.tracer.StartSpan(ctx, SpanLoad, c.spanOptions())
+	defer func() { span.End(err) }()
+
 	// 校验组件名称并构造map
-	configMap := make(map[ComponentName]ComponentConfig)
-	for _, cfg := range configs {
-		if !cfg.Name.Validate() {
-			return fmt.Errorf("%w, name: %s, type: %s", ErrComponentNameInvalid, cfg.Name, cfg.Type)
-		}
-		if _, ok := configMap[cfg.Name]; ok {
-			return fmt.Errorf("%w, name: %s", ErrComponentAlreadyExists, cfg.Name)
-		}
-		configMap[cfg.Name] = cfg
+	configMap, err := newConfigMap(configs)
+	if err != nil {
+		return
+	}
+	// 过滤掉被禁用的组件
+	skipped, err := c.conditionEnv().filter(configMap)
+	if err != nil {
+		return
+	}
+	if len(skipped) > 0 {
+		c.log().Info("components skipped", "names", skipped)
+	}
+	c.mu.RLock()
+	existing := maps.Clone(c.configs)
+	c.mu.RUnlock()
+	inferGroupDeps(c.factoryRegistry, configMap, existing)
+	if err = checkScopes(configMap, c.declaredConfig); err != nil {
+		return
 	}
 
 	// 拓扑排序
 	var orders []ComponentName
+	dag := make(map[ComponentName]set[ComponentName])
 	{
 		// 构建组件依赖图
-		dag := make(map[ComponentName]set[ComponentName])
-		for _, cfg := range configs {
-			name := cfg.Name
+		for name, cfg := range configMap {
 			if _, ok := dag[name]; !ok {
 				dag[name] = make(map[ComponentName]struct{})
 			}
 			for _, dep := range cfg.Deps {
+				dep, optional := parseDep(dep)
 				// 已存在的依赖关系则不加入本次的DAG构建
 				c.mu.RLock()
 				_, ok := c.components[dep]
@@ -151,32 +239,242 @@ func (c *ComponentContainer) LoadNamedComponents(configs []ComponentConfig) (err
 				if ok {
 					continue
 				}
-				dag[cfg.Name][dep] = struct{}{}
+				// 不存在的可选依赖不参与排序
+				if _, inBatch := configMap[dep]; optional && !inBatch {
+					continue
+				}
+				dag[name][dep] = struct{}{}
 			}
 		}
 
 		// 对新组件集合进行拓扑排序
 		orders, err = topologicalSort(dag)
 		if err != nil {
+			c.log().Error("sort components failed", "error", err)
 			return
 		}
 	}
+	c.log().Debug("build order", "names", orders)
 
+	if c.parallelism > 1 {
+		return c.loadParallel(ctx, orders, dag, configMap)
+	}
 	for _, name := range orders {
-		component, err := c.MustLoadComponent(configMap[name])
-		if err != nil {
-			return err
+		component, loadErr := c.loadNamedComponent(ctx, configMap[name])
+		if loadErr != nil {
+			c.log().Error("load components failed", "name", name, "error", loadErr)
+			return loadErr
 		}
 		c.mu.Lock()
 		c.components[name] = component
+		c.configs[name] = configMap[name]
 		c.mu.Unlock()
 	}
 	return
 }
 
-// UnloadNamedComponents implements IComponentRegistry.
-func (c *ComponentContainer) UnloadNamedComponents(name []ComponentName, recursive bool) error {
-	panic("unimplemented")
+// 获取组件类型对应的工厂，构造函数组件使用内置的工厂
+func (c *ComponentContainer) getFactory(t ComponentTypeID) (IComponentFactory, error) {
+	if t == ProviderComponentType {
+		return providerFactory{}, nil
+	}
+	return c.factoryRegistry.GetFactory(t)
+}
+
+// 加载一个具名组件，非单例组件与懒加载组件只校验并记录定义，实例在GetComponent时创建
+func (c *ComponentContainer) loadNamedComponent(ctx context.Context, config ComponentConfig) (component Component, err error) {
+	if config.Scope.isSingleton() && !config.Lazy {
+		return c.loadComponent(ctx, "", config)
+	}
+	if _, err = c.getFactory(config.Type); err != nil {
+		return
+	}
+	for _, dep := range config.Deps {
+		if name, optional := parseDep(dep); !optional && !c.hasComponent(name) {
+			err = fmt.Errorf("%w, dependency %s not found", ErrComponentDependencyNotFound, dep)
+			return
+		}
+	}
+	component = Component{BuildContext: BuildContext{Config: config, Container: c}}
+	if config.Scope.isSingleton() {
+		component.lazy = &lazyComponent{}
+	}
+	return
+}
+
+// DestroyComponent 销毁一个由调用方负责生命周期的组件实例，如瞬态组件每次获取得到的实例、
+// 直接通过容器加载的匿名组件。容器管理的具名单例组件应通过UnloadNamedComponents卸载
+func (c *ComponentContainer) DestroyComponent(component Component) error {
+	return c.destroyComponent(component)
+}
+
+// 销毁一个组件实例，引用自其他容器的组件不归当前容器管理，不做销毁
+func (c *ComponentContainer) destroyComponent(component Component) (err error) {
+	return c.destroyComponentWithin(c.context.Context(), component)
+}
+
+// 销毁一个组件实例，组件销毁的span是parent中span的子span
+func (c *ComponentContainer) destroyComponentWithin(parent context.Context, component Component) (err error) {
+	if component.lazy != nil { // 懒加载组件只销毁已创建的实例，正在创建的实例等待其创建结束
+		var created bool
+		if component, created = component.lazy.awaitCreated(); !created {
+			return
+		}
+	}
+	if component.BuildContext.Container != IComponentContainer(c) || component.BuildContext.Mount == nil {
+		return
+	}
+	factory, err := c.getFactory(component.BuildContext.Config.Type)
+	if err != nil {
+		return
+	}
+	ctx, span := c.tracer.StartSpan(parent, SpanDestroy, componentSpanOptions(component.BuildContext))
+	defer func() { span.End(err) }()
+	c.emit(&Event{Type: EventBeforeDestroy, Time: time.Now(), Context: component.BuildContext})
+	start := time.Now()
+	err = factory.DestroyInstance(component.BuildContext, component.Instance)
+	event := Event{Type: EventAfterDestroy, Time: time.Now(), Context: component.BuildContext, Duration: time.Since(start), Err: err}
+	if err != nil {
+		event.Type = EventDestroyFailed
+	}
+	c.emit(&event)
+
+	// 组件销毁后，逆序销毁其构造时加载的匿名组件
+	return errors.Join(err, c.destroyOwned(ctx, component.BuildContext.owned))
+}
+
+// 逆序销毁组件构造时加载的匿名组件，并清空记录
+func (c *ComponentContainer) destroyOwned(parent context.Context, owned *ownedComponents) error {
+	if owned == nil {
+		return nil
+	}
+	owned.mu.Lock()
+	components := owned.components
+	owned.components = nil
+	owned.mu.Unlock()
+
+	var errs []error
+	for _, child := range slices.Backward(components) {
+		if cc, ok := child.BuildContext.Container.(*ComponentContainer); ok {
+			if childErr := cc.destroyComponentWithin(parent, child); childErr != nil {
+				errs = append(errs, fmt.Errorf("destroy anonymous component of type %s: %w", child.BuildContext.Config.Type, childErr))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// InspectComponent implements IComponentInspector.
+func (c *ComponentContainer) InspectComponent(name ComponentName) (info ComponentInfo, err error) {
+	c.mu.RLock()
+	component, ok := c.components[name]
+	cfg, declared := c.configs[name]
+	c.mu.RUnlock()
+	if !ok {
+		err = fmt.Errorf("%w, name: %s", ErrComponentNameNotFound, name)
+		return
+	}
+	if !declared { // 直接放入或作用域内创建的组件
+		cfg = component.BuildContext.Config
+	}
+	info = ComponentInfo{Name: name, Config: cfg, State: ComponentStateReady, Component: component}
+	switch {
+	case declared && !cfg.Scope.isSingleton():
+		info.State = ComponentStateDeferred
+	case component.lazy != nil:
+		info.State, info.Component, info.Err = component.lazy.state(component)
+	}
+	return
+}
+
+// ListComponents implements IComponentLister.
+func (c *ComponentContainer) ListComponents(selector Selector) (components []Component) {
+	for _, name := range c.LoadedComponentNames() {
+		c.mu.RLock()
+		component, ok := c.components[name]
+		labels := component.BuildContext.Config.Labels
+		cfg, declared := c.configs[name]
+		if declared { // 引用组件以声明配置中的标签为准
+			labels = cfg.Labels
+		}
+		c.mu.RUnlock()
+		if !cfg.Scope.isSingleton() { // 非单例组件没有固定的实例
+			continue
+		}
+		if !ok || !selector.Matches(labels) {
+			continue
+		}
+		if component.lazy != nil { // 懒加载组件在被查询时创建，创建失败的组件不返回
+			var err error
+			if component, err = c.getLazyComponent(component); err != nil {
+				continue
+			}
+		}
+		components = append(components, component)
+	}
+	return
+}
+
+// UnloadNamedComponents implements IComponentRegistry. 按依赖关系的逆序卸载并销毁组件，
+// 若组件仍被其他组件依赖，recursive为true时一并卸载依赖方，否则返回错误
+func (c *ComponentContainer) UnloadNamedComponents(names []ComponentName, recursive bool) error {
+	c.dispatcher.hold() // 持有锁期间的事件在解锁后通知
+	defer c.dispatcher.release()
+	c.reconcileMu.Lock()
+	defer c.reconcileMu.Unlock()
+
+	orders := c.LoadedComponentNames()
+	c.mu.RLock()
+	dependents := make(map[ComponentName][]ComponentName)
+	for name, component := range c.components {
+		for _, dep := range c.componentDeps(name, component) {
+			dep, _ := parseDep(dep)
+			dependents[dep] = append(dependents[dep], name)
+		}
+	}
+	c.mu.RUnlock()
+
+	unload := make(set[ComponentName])
+	queue := slices.Clone(names)
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		if _, ok := unload[name]; ok {
+			continue
+		}
+		if !slices.Contains(orders, name) {
+			return fmt.Errorf("%w, name: %s", ErrComponentNameNotFound, name)
+		}
+		unload[name] = struct{}{}
+		for _, dependent := range dependents[name] {
+			if !recursive && !slices.Contains(names, dependent) {
+				return fmt.Errorf("%w, component %s is depended on by %s", ErrComponentHasDependents, name, dependent)
+			}
+			queue = append(queue, dependent)
+		}
+	}
+
+	var errs []error
+	for _, name := range slices.Backward(orders) {
+		if _, ok := unload[name]; !ok {
+			continue
+		}
+		c.mu.Lock()
+		component := c.components[name]
+		delete(c.components, name)
+		delete(c.configs, name)
+		c.mu.Unlock()
+		// 已启动的组件先停止再销毁
+		c.lifecycleMu.Lock()
+		if err := c.stopForTeardown(c.context.Context(), []ComponentName{name}, map[ComponentName]Component{name: component}); err != nil {
+			errs = append(errs, err)
+		}
+		c.lifecycleMu.Unlock()
+		if err := c.destroyComponent(component); err != nil {
+			errs = append(errs, fmt.Errorf("destroy component %s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
 }
 
 // LoadedComponentNames implements IComponentRegistry.
@@ -186,12 +484,16 @@ func (c *ComponentContainer) LoadedComponentNames() (names []ComponentName) {
 
 	// 构建组件依赖图
 	dag := make(map[ComponentName]set[ComponentName])
-	for _, cfg := range c.components {
-		name := cfg.BuildContext.Config.Name
+	for name, component := range c.components {
 		if _, ok := dag[name]; !ok {
 			dag[name] = make(map[ComponentName]struct{})
 		}
-		for _, dep := range cfg.BuildContext.Config.Deps {
+		for _, dep := range c.componentDeps(name, component) {
+			dep, _ := parseDep(dep)
+			// 缺失的可选依赖与作用域所属容器中的依赖不参与排序
+			if _, ok := c.components[dep]; !ok {
+				continue
+			}
 			dag[name][dep] = struct{}{}
 		}
 	}
@@ -205,33 +507,76 @@ func (c *ComponentContainer) LoadedComponentNames() (names []ComponentName) {
 	return
 }
 
+// 组件声明的依赖，调用方需持有c.mu。引用组件的BuildContext是被引用组件的，因此优先取声明的配置，
+// 直接放入的组件取其BuildContext中的配置
+func (c *ComponentContainer) componentDeps(name ComponentName, component Component) []ComponentName {
+	if cfg, ok := c.configs[name]; ok {
+		return cfg.Deps
+	}
+	return component.BuildContext.Config.Deps
+}
+
+// 组件条件加载的求值环境
+func (c *ComponentContainer) conditionEnv() conditionEnv {
+	return conditionEnv{lookupEnv: os.LookupEnv, profiles: c.profiles}
+}
+
 type options struct {
 	factoryRegistry IFactoryRegistry
 	parent          IComponentContainer
 	context         BuildContext
+	profiles        []string
+	logger          *slog.Logger
+	parallelism     int
+	metrics         *metricsRecorder
+	tracer          ITracer
+	supervisor      *SupervisorOptions
+	retryableErrors []error
 }
 
-type optionsFunc func(o *options)
+// 创建容器的选项，由With开头的函数构造
+type Option func(o *options)
 
-func WithFactoryRegistry(factoryRegistry IFactoryRegistry) optionsFunc {
+func WithFactoryRegistry(factoryRegistry IFactoryRegistry) Option {
 	return func(o *options) {
 		o.factoryRegistry = factoryRegistry
 	}
 }
 
-func WithParentContainer(parent IComponentContainer) optionsFunc {
+func WithParentContainer(parent IComponentContainer) Option {
 	return func(o *options) {
 		o.parent = parent
 	}
 }
 
-func WithContext(ctx BuildContext) optionsFunc {
+func WithContext(ctx BuildContext) Option {
 	return func(o *options) {
 		o.context = ctx
 	}
 }
 
-func NewComponentContainer(optFns ...optionsFunc) (cr IComponentContainer) {
+// 设置容器启用的profile，未设置时继承父容器的profile
+func WithProfiles(profiles ...string) Option {
+	return func(o *options) {
+		o.profiles = profiles
+	}
+}
+
+// 设置容器的日志记录器，未设置时继承父容器的日志记录器，均未设置时不输出日志
+func WithLogger(logger *slog.Logger) Option {
+	return func(o *options) {
+		o.logger = logger
+	}
+}
+
+// 设置并行加载组件的最大并发数，互不依赖的组件会被并行构造，未设置时继承父容器的设置
+func WithParallelism(n int) Option {
+	return func(o *options) {
+		o.parallelism = n
+	}
+}
+
+func NewComponentContainer(optFns ...Option) (cr IComponentContainer) {
 	var opt options
 	for _, fn := range optFns {
 		fn(&opt)
@@ -240,10 +585,55 @@ func NewComponentContainer(optFns ...optionsFunc) (cr IComponentContainer) {
 	if opt.factoryRegistry == nil {
 		opt.factoryRegistry = DefaultFactoryRegistry
 	}
+	events, dispatcher := &eventLog{}, &eventDispatcher{}
+	profiler := &profiler{}
+	if parent, ok := opt.parent.(*ComponentContainer); ok {
+		if opt.profiles == nil {
+			opt.profiles = parent.profiles
+		}
+		events, dispatcher = parent.events, parent.dispatcher
+		profiler = parent.profiler
+		if opt.tracer == nil {
+			opt.tracer = parent.tracer
+		}
+		if opt.retryableErrors == nil {
+			opt.retryableErrors = parent.retryableErrors
+		}
+		if opt.metrics == nil {
+			opt.metrics = parent.metrics
+		}
+		if opt.parallelism == 0 {
+			opt.parallelism = parent.parallelism
+		}
+		if opt.logger == nil {
+			opt.logger = parent.logger
+		}
+	}
+	if opt.logger == nil {
+		opt.logger = discardLogger
+	}
+	if opt.tracer == nil {
+		opt.tracer = NoopTracer{}
+	}
+	var supervisor *supervisor
+	if opt.supervisor != nil {
+		supervisor = newSupervisor(*opt.supervisor)
+	}
 	return &ComponentContainer{
 		context:         opt.context,
 		factoryRegistry: opt.factoryRegistry,
 		parent:          opt.parent,
 		components:      make(map[ComponentName]Component),
+		configs:         make(map[ComponentName]ComponentConfig),
+		profiles:        opt.profiles,
+		events:          events,
+		dispatcher:      dispatcher,
+		logger:          opt.logger,
+		parallelism:     opt.parallelism,
+		profiler:        profiler,
+		metrics:         opt.metrics,
+		tracer:          opt.tracer,
+		supervisor:      supervisor,
+		retryableErrors: opt.retryableErrors,
 	}
 }
@@ -1,6 +1,9 @@
 package compcont
 
 import (
+	"bytes"
+	"errors"
+	"log/slog"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -90,3 +93,187 @@ func Test(t *testing.T) {
 
 	assert.Equal(t, "testa", componentB.Instance.GetConfigB().InnerA.Config.TestA)
 }
+
+func TestResolveComponent(t *testing.T) {
+	registry := NewFactoryRegistry()
+	MustRegister(registry, factoryA)
+	root := NewComponentContainer(WithFactoryRegistry(registry))
+	assert.NoError(t, root.LoadNamedComponents([]ComponentConfig{{Name: "a1", Type: "a"}}))
+	child := NewComponentContainer(WithFactoryRegistry(registry), WithParentContainer(root))
+
+	_, err := ResolveComponent[IComponentA](child, false)
+	assert.ErrorIs(t, err, ErrComponentNameNotFound)
+	a, err := ResolveComponent[IComponentA](child, true)
+	assert.NoError(t, err)
+	assert.Equal(t, ComponentName("a1"), a.BuildContext.Config.Name)
+
+	assert.NoError(t, root.LoadNamedComponents([]ComponentConfig{{Name: "a2", Type: "a", Deps: []ComponentName{"a1"}}}))
+	_, err = ResolveComponent[IComponentA](child, true)
+	assert.ErrorIs(t, err, ErrComponentAmbiguous)
+	assert.ErrorContains(t, err, "candidates: a1, a2")
+
+	all, err := ResolveComponents[IComponentA](root)
+	assert.NoError(t, err)
+	assert.Len(t, all, 2)
+}
+
+func TestResolveComponentWithoutCreating(t *testing.T) {
+	var created int
+	registry := NewFactoryRegistry()
+	MustRegister(registry, &TypedSimpleComponentFactory[ConfigA, IComponentA]{
+		TypeID: "a",
+		CreateInstanceFunc: func(ctx BuildContext, config ConfigA) (component IComponentA, err error) {
+			created++
+			return factoryA.CreateInstanceFunc(ctx, config)
+		},
+	})
+	MustRegister(registry, factoryB)
+	container := NewComponentContainer(WithFactoryRegistry(registry))
+	assert.NoError(t, container.LoadNamedComponents([]ComponentConfig{
+		{Name: "lazy", Type: "a", Lazy: true},
+		{Name: "transient", Type: "a", Scope: ScopeTransient},
+	}))
+
+	// 查找其他类型不会创建懒加载与瞬态组件
+	_, err := ResolveComponent[IComponentB](container, false)
+	assert.ErrorIs(t, err, ErrComponentNameNotFound)
+	assert.Equal(t, 0, created)
+
+	// 按工厂声明的实例类型匹配，瞬态组件不参与ResolveComponents
+	all, err := ResolveComponents[IComponentA](container)
+	assert.NoError(t, err)
+	assert.Len(t, all, 1)
+	assert.Equal(t, ComponentName("lazy"), all[0].BuildContext.Config.Name)
+	assert.Equal(t, 1, created)
+	_, err = ResolveComponent[IComponentA](container, false)
+	assert.ErrorIs(t, err, ErrComponentAmbiguous)
+	assert.Equal(t, 1, created)
+
+	// 懒加载组件创建前后按同一个声明类型匹配
+	container = NewComponentContainer(WithFactoryRegistry(registry))
+	assert.NoError(t, container.LoadNamedComponents([]ComponentConfig{{Name: "lazy", Type: "a", Lazy: true}}))
+	for range 2 {
+		_, err = ResolveComponent[*ComponentA](container, false)
+		assert.ErrorIs(t, err, ErrComponentNameNotFound)
+		lazy, err := ResolveComponent[IComponentA](container, false)
+		assert.NoError(t, err)
+		assert.IsType(t, &ComponentA{}, lazy.Instance)
+	}
+}
+
+// 通过BuildContext加载内部组件，内部组件归属于正在构造的组件
+func createOwningB(ctx BuildContext, config ConfigB) (component IComponentB, err error) {
+	componentA, err := config.InnerA.LoadComponent(ctx)
+	if err != nil {
+		return
+	}
+	if config.TestB == "fail" {
+		err = errors.New("create b failed")
+		return
+	}
+	component = &ComponentB{
+		ConfigB:    config,
+		componentA: componentA.Instance,
+	}
+	return
+}
+
+var factoryOwningB = &TypedSimpleComponentFactory[ConfigB, IComponentB]{
+	TypeID:             "b",
+	CreateInstanceFunc: createOwningB,
+}
+
+func TestOwnedAnonymousComponents(t *testing.T) {
+	var destroyed []string
+	registry := NewFactoryRegistry()
+	MustRegister(registry, &TypedSimpleComponentFactory[ConfigA, IComponentA]{
+		TypeID:             "a",
+		CreateInstanceFunc: factoryA.CreateInstanceFunc,
+		DestroyInstanceFunc: func(ctx BuildContext, instance IComponentA) (err error) {
+			destroyed = append(destroyed, "a:"+instance.GetConfigA().TestA)
+			return
+		},
+	})
+	MustRegister(registry, &TypedSimpleComponentFactory[ConfigB, IComponentB]{
+		TypeID:             "b",
+		CreateInstanceFunc: createOwningB,
+		DestroyInstanceFunc: func(ctx BuildContext, instance IComponentB) (err error) {
+			destroyed = append(destroyed, "b:"+instance.GetConfigB().TestB)
+			return
+		},
+	})
+	container := NewComponentContainer(WithFactoryRegistry(registry))
+	err := container.LoadNamedComponents([]ComponentConfig{
+		{Name: "cb", Type: "b", Config: ConfigB{
+			TestB:  "testb",
+			InnerA: TypedComponentConfig[ConfigA, IComponentA]{Type: "a", Config: ConfigA{TestA: "inner"}},
+		}},
+		{Name: "cb2", Type: "b", Deps: []ComponentName{"cb"}, Config: ConfigB{
+			TestB:  "testb2",
+			InnerA: TypedComponentConfig[ConfigA, IComponentA]{Type: "a", Config: ConfigA{TestA: "inner2"}},
+		}},
+	})
+	assert.NoError(t, err)
+
+	cb, err := container.GetComponent("cb")
+	assert.NoError(t, err)
+	owned := cb.BuildContext.OwnedComponents()
+	assert.Len(t, owned, 1)
+	assert.Equal(t, ComponentTypeID("a"), owned[0].BuildContext.Config.Type)
+
+	assert.ErrorIs(t, container.UnloadNamedComponents([]ComponentName{"cb"}, false), ErrComponentHasDependents)
+	assert.NoError(t, container.UnloadNamedComponents([]ComponentName{"cb"}, true))
+	assert.Equal(t, []string{"b:testb2", "a:inner2", "b:testb", "a:inner"}, destroyed)
+	assert.Empty(t, container.LoadedComponentNames())
+
+	// 构造失败时已加载的内部组件随之销毁
+	destroyed = nil
+	err = container.LoadNamedComponents([]ComponentConfig{
+		{Name: "broken", Type: "b", Config: ConfigB{
+			TestB:  "fail",
+			InnerA: TypedComponentConfig[ConfigA, IComponentA]{Type: "a", Config: ConfigA{TestA: "orphan"}},
+		}},
+	})
+	assert.ErrorContains(t, err, "create b failed")
+	assert.Equal(t, []string{"a:orphan"}, destroyed)
+}
+
+func TestUnownedAnonymousComponentWarning(t *testing.T) {
+	var logs bytes.Buffer
+	registry := NewFactoryRegistry()
+	MustRegister(registry, factoryA)
+	MustRegister(registry, factoryB)
+	container := NewComponentContainer(WithFactoryRegistry(registry), WithLogger(slog.New(slog.NewTextHandler(&logs, nil))))
+	err := container.LoadNamedComponents([]ComponentConfig{
+		{Name: "cb", Type: "b", Config: ConfigB{
+			InnerA: TypedComponentConfig[ConfigA, IComponentA]{Type: "a"},
+		}},
+	})
+	assert.NoError(t, err)
+	assert.Contains(t, logs.String(), "not owned by any component")
+	cb, err := container.GetComponent("cb")
+	assert.NoError(t, err)
+	assert.Empty(t, cb.BuildContext.OwnedComponents())
+}
+
+func TestReferComponentDeps(t *testing.T) {
+	var log []string
+	registry := NewFactoryRegistry()
+	MustRegister(registry, newLifecycleFactory(&log))
+	MustRegister(registry, newContainerFactory(registry))
+	container := NewComponentContainer(WithFactoryRegistry(registry))
+	err := container.LoadNamedComponents([]ComponentConfig{
+		{Name: "infra", Type: "container", Config: containerConfig{Components: []ComponentConfig{
+			{Name: "db", Type: "svc"},
+		}}},
+		{Name: "db", Refer: "/infra/db", Deps: []ComponentName{"infra"}},
+	})
+	assert.NoError(t, err)
+
+	// 引用组件按自身声明的依赖排序与卸载
+	assert.Equal(t, []ComponentName{"infra", "db"}, container.LoadedComponentNames())
+	err = container.UnloadNamedComponents([]ComponentName{"infra"}, false)
+	assert.ErrorIs(t, err, ErrComponentHasDependents)
+	assert.NoError(t, container.UnloadNamedComponents([]ComponentName{"infra"}, true))
+	assert.Empty(t, container.LoadedComponentNames())
+}
@@ -0,0 +1,329 @@
+// Package debughttp 提供只读的容器状态调试页面，可选地开放重新加载配置、重启组件等管理操作
+package debughttp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	compcont "github.com/go-compcont/compcont-core"
+)
+
+// 默认需要脱敏的配置字段，字段名包含其中任一关键字（不区分大小写）即脱敏
+var DefaultRedactKeys = []string{"password", "passwd", "secret", "token", "credential", "private_key", "privatekey", "apikey", "api_key"}
+
+const redacted = "******"
+
+type Options struct {
+	EnableAdmin bool                            // 是否开放管理操作，默认只读
+	Reload      func(ctx context.Context) error // 重新加载配置，为空时不提供reload操作
+	RedactKeys  []string                        // 需要脱敏的配置字段关键字，为空时使用DefaultRedactKeys
+	Logger      *slog.Logger                    // 记录写响应失败的日志，为空时使用slog.Default()
+}
+
+type handler struct {
+	container compcont.IComponentContainer
+	opt       Options
+	mux       *http.ServeMux
+}
+
+// NewHandler 创建调试页面的http.Handler，各页面默认输出JSON，请求参数format=html时输出HTML：
+//
+//	GET  /                 页面索引
+//	GET  /tree             容器树中所有组件的路径、类型、状态与依赖
+//	GET  /graph            依赖图，format可选json、dot、mermaid
+//	GET  /configs          脱敏后的组件声明配置
+//	GET  /factories        已注册的组件类型
+//	GET  /events           最近的组件生命周期事件
+//	POST /admin/reload     重新加载配置，需开启EnableAdmin并提供Reload
+//	POST /admin/restart    重启path参数指定的组件及其依赖方，引用组件重启其引用的组件并返回实际重启的路径，需开启EnableAdmin
+func NewHandler(container compcont.IComponentContainer, opt Options) http.Handler {
+	if opt.RedactKeys == nil {
+		opt.RedactKeys = DefaultRedactKeys
+	}
+	if opt.Logger == nil {
+		opt.Logger = slog.Default()
+	}
+	h := &handler{container: container, opt: opt, mux: http.NewServeMux()}
+	h.mux.HandleFunc("GET /{$}", h.index)
+	h.mux.HandleFunc("GET /tree", h.tree)
+	h.mux.HandleFunc("GET /graph", h.graph)
+	h.mux.HandleFunc("GET /configs", h.configs)
+	h.mux.HandleFunc("GET /factories", h.factories)
+	h.mux.HandleFunc("GET /events", h.events)
+	h.mux.HandleFunc("POST /admin/reload", h.admin(h.reload))
+	h.mux.HandleFunc("POST /admin/restart", h.admin(h.restart))
+	return h
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+type componentView struct {
+	Path   string                   `json:"path"`
+	Name   compcont.ComponentName   `json:"name"`
+	Type   compcont.ComponentTypeID `json:"type"`
+	Refer  string                   `json:"refer,omitempty"`
+	State  compcont.ComponentState  `json:"state"`
+	Owner  string                   `json:"owner,omitempty"`
+	Deps   []compcont.ComponentName `json:"deps"`
+	Scope  string                   `json:"scope"`
+	Lazy   bool                     `json:"lazy"`
+	Labels map[string]string        `json:"labels,omitempty"`
+	Error  string                   `json:"error,omitempty"`
+}
+
+func (h *handler) tree(w http.ResponseWriter, r *http.Request) {
+	var views []componentView
+	err := compcont.Walk(h.container, func(node compcont.WalkNode) error {
+		view := componentView{
+			Path:   node.Path,
+			Name:   node.Name,
+			Type:   node.Config.Type,
+			Refer:  node.Config.Refer,
+			State:  node.State,
+			Owner:  node.Owner,
+			Deps:   node.Config.Deps,
+			Scope:  node.Config.Scope.String(),
+			Lazy:   node.Config.Lazy,
+			Labels: node.Config.Labels,
+		}
+		if node.Err != nil {
+			view.Error = node.Err.Error()
+		}
+		views = append(views, view)
+		return nil
+	})
+	if err != nil {
+		h.writeError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	h.writeData(w, r, "Components", views)
+}
+
+func (h *handler) graph(w http.ResponseWriter, r *http.Request) {
+	graph, err := compcont.BuildGraph(h.container, compcont.GraphOptions{Root: r.URL.Query().Get("root")})
+	if err != nil {
+		h.writeError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	switch r.URL.Query().Get("format") {
+	case "dot":
+		w.Header().Set("Content-Type", "text/vnd.graphviz; charset=utf-8")
+		h.logWrite(r, graph.WriteDOT(w))
+	case "mermaid":
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		h.logWrite(r, graph.WriteMermaid(w))
+	default:
+		h.writeData(w, r, "Dependency Graph", graph)
+	}
+}
+
+type configView struct {
+	Path   string                   `json:"path"`
+	Type   compcont.ComponentTypeID `json:"type"`
+	Config any                      `json:"config"`
+	Error  string                   `json:"error,omitempty"` // 配置无法序列化时的原因，此时config为null
+}
+
+func (h *handler) configs(w http.ResponseWriter, r *http.Request) {
+	var views []configView
+	err := compcont.Walk(h.container, func(node compcont.WalkNode) error {
+		view := configView{Path: node.Path, Type: node.Config.Type}
+		config, err := h.redact(node.Config.Config)
+		if err != nil { // 单个组件的配置无法展示时不影响其他组件
+			view.Error = fmt.Sprintf("redact config: %s", err)
+		} else {
+			view.Config = config
+		}
+		views = append(views, view)
+		return nil
+	})
+	if err != nil {
+		h.writeError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	h.writeData(w, r, "Effective Configs", views)
+}
+
+func (h *handler) factories(w http.ResponseWriter, r *http.Request) {
+	types := h.container.FactoryRegistry().RegisteredComponentTypes()
+	slices.Sort(types)
+	h.writeData(w, r, "Registered Factories", types)
+}
+
+type eventView struct {
+	Type     compcont.EventType       `json:"type"`
+	Time     time.Time                `json:"time"`
+	Path     string                   `json:"path"`
+	Kind     compcont.ComponentTypeID `json:"component_type"`
+	Duration string                   `json:"duration"`
+	Error    string                   `json:"error,omitempty"`
+}
+
+func (h *handler) events(w http.ResponseWriter, r *http.Request) {
+	source, ok := h.container.(interface{ RecentEvents() []compcont.Event })
+	if !ok {
+		h.writeError(w, r, http.StatusNotImplemented, errors.New("container does not record events"))
+		return
+	}
+	events := source.RecentEvents()
+	views := make([]eventView, 0, len(events))
+	for _, e := range slices.Backward(events) { // 最近的事件在前
+		view := eventView{Type: e.Type, Time: e.Time, Path: e.Path(), Kind: e.Context.Config.Type, Duration: e.Duration.String()}
+		if e.Err != nil {
+			view.Error = e.Err.Error()
+		}
+		views = append(views, view)
+	}
+	h.writeData(w, r, "Recent Events", views)
+}
+
+// 管理操作需要显式开启
+func (h *handler) admin(fn http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !h.opt.EnableAdmin {
+			h.writeError(w, r, http.StatusForbidden, errors.New("admin actions are disabled"))
+			return
+		}
+		fn(w, r)
+	}
+}
+
+func (h *handler) reload(w http.ResponseWriter, r *http.Request) {
+	if h.opt.Reload == nil {
+		h.writeError(w, r, http.StatusNotImplemented, errors.New("reload is not configured"))
+		return
+	}
+	if err := h.opt.Reload(r.Context()); err != nil {
+		h.writeError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	h.writeData(w, r, "Reload", map[string]string{"status": "ok"})
+}
+
+func (h *handler) restart(w http.ResponseWriter, r *http.Request) {
+	path := r.URL.Query().Get("path")
+	component, err := compcont.Resolve(h.container, path)
+	if err != nil {
+		h.writeError(w, r, http.StatusNotFound, err)
+		return
+	}
+	container, ok := component.BuildContext.Container.(interface {
+		RestartComponents(names ...compcont.ComponentName) error
+	})
+	if !ok {
+		h.writeError(w, r, http.StatusNotImplemented, errors.New("container does not support restarting components"))
+		return
+	}
+	if err = container.RestartComponents(component.BuildContext.Config.Name); err != nil {
+		h.writeError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	// 引用组件解析到的是被引用的组件，返回实际重启的路径
+	var restarted strings.Builder
+	for _, name := range component.BuildContext.GetAbsolutePath() {
+		restarted.WriteString("/" + name.String())
+	}
+	h.writeData(w, r, "Restart", map[string]string{"status": "ok", "path": restarted.String()})
+}
+
+// 将配置转换为通用结构后脱敏
+func (h *handler) redact(config any) (ret any, err error) {
+	data, err := json.Marshal(config)
+	if err != nil {
+		return
+	}
+	if err = json.Unmarshal(data, &ret); err != nil {
+		return
+	}
+	return h.redactValue(ret), nil
+}
+
+func (h *handler) redactValue(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for key, value := range v {
+			if h.sensitive(key) {
+				v[key] = redacted
+			} else {
+				v[key] = h.redactValue(value)
+			}
+		}
+	case []any:
+		for i, value := range v {
+			v[i] = h.redactValue(value)
+		}
+	}
+	return v
+}
+
+func (h *handler) sensitive(key string) bool {
+	key = strings.ToLower(key)
+	return slices.ContainsFunc(h.opt.RedactKeys, func(k string) bool {
+		return strings.Contains(key, strings.ToLower(k))
+	})
+}
+
+var indexTemplate = template.Must(template.New("index").Parse(`<!DOCTYPE html>
+<html><head><title>compcont</title></head><body>
+<h1>compcont</h1>
+<ul>
+<li><a href="tree?format=html">tree</a></li>
+<li><a href="graph?format=html">graph</a> (<a href="graph?format=dot">dot</a>, <a href="graph?format=mermaid">mermaid</a>)</li>
+<li><a href="configs?format=html">configs</a></li>
+<li><a href="factories?format=html">factories</a></li>
+<li><a href="events?format=html">events</a></li>
+</ul>
+{{if .}}<p>admin actions are enabled</p>{{end}}
+</body></html>`))
+
+var dataTemplate = template.Must(template.New("data").Parse(`<!DOCTYPE html>
+<html><head><title>{{.Title}}</title></head><body>
+<p><a href="./">index</a></p>
+<h1>{{.Title}}</h1>
+<pre>{{.Body}}</pre>
+</body></html>`))
+
+func (h *handler) index(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	h.logWrite(r, indexTemplate.Execute(w, h.opt.EnableAdmin))
+}
+
+// 响应头已经写出，写响应失败时只能记录日志
+func (h *handler) logWrite(r *http.Request, err error) {
+	if err != nil {
+		h.opt.Logger.Error("write debug response failed", "path", r.URL.Path, "error", err)
+	}
+}
+
+// 按请求的格式输出数据
+func (h *handler) writeData(w http.ResponseWriter, r *http.Request, title string, data any) {
+	body, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		h.writeError(w, r, http.StatusInternalServerError, err)
+		return
+	}
+	if r.URL.Query().Get("format") == "html" {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		h.logWrite(r, dataTemplate.Execute(w, map[string]string{"Title": title, "Body": string(body)}))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, err = w.Write(append(body, '\n'))
+	h.logWrite(r, err)
+}
+
+func (h *handler) writeError(w http.ResponseWriter, r *http.Request, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	h.logWrite(r, json.NewEncoder(w).Encode(map[string]string{"error": err.Error()}))
+}
@@ -0,0 +1,100 @@
+package debughttp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	compcont "github.com/go-compcont/compcont-core"
+	"github.com/stretchr/testify/assert"
+)
+
+type dbConfig struct {
+	DSN      string `ccf:"dsn"`
+	Password string `ccf:"password"`
+}
+
+func newTestContainer(t *testing.T) (compcont.IComponentContainer, *int) {
+	created := new(int)
+	registry := compcont.NewFactoryRegistry()
+	compcont.MustRegister(registry, &compcont.TypedSimpleComponentFactory[dbConfig, string]{
+		TypeID: "db",
+		CreateInstanceFunc: func(ctx compcont.BuildContext, config dbConfig) (instance string, err error) {
+			*created++
+			return config.DSN, nil
+		},
+	})
+	container := compcont.NewComponentContainer(compcont.WithFactoryRegistry(registry))
+	err := container.LoadNamedComponents([]compcont.ComponentConfig{
+		{Name: "db", Type: "db", Config: dbConfig{DSN: "mysql://db", Password: "hunter2"}},
+	})
+	assert.NoError(t, err)
+	return container, created
+}
+
+func get(t *testing.T, h http.Handler, method, target string, v any) int {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(method, target, nil))
+	if v != nil {
+		assert.NoError(t, json.Unmarshal(rec.Body.Bytes(), v), rec.Body.String())
+	}
+	return rec.Code
+}
+
+func TestHandler(t *testing.T) {
+	container, created := newTestContainer(t)
+	h := NewHandler(container, Options{})
+
+	var tree []componentView
+	assert.Equal(t, http.StatusOK, get(t, h, "GET", "/tree", &tree))
+	assert.Equal(t, "/db", tree[0].Path)
+	assert.Equal(t, compcont.ComponentStateReady, tree[0].State)
+
+	var configs []configView
+	assert.Equal(t, http.StatusOK, get(t, h, "GET", "/configs", &configs))
+	assert.Equal(t, map[string]any{"DSN": "mysql://db", "Password": redacted}, configs[0].Config)
+
+	// 无法序列化的配置只影响对应的条目
+	assert.NoError(t, container.PutComponent("raw", compcont.Component{BuildContext: compcont.BuildContext{
+		Container: container,
+		Config:    compcont.ComponentConfig{Name: "raw", Type: "db", Config: make(chan int)},
+	}}))
+	configs = nil
+	assert.Equal(t, http.StatusOK, get(t, h, "GET", "/configs", &configs))
+	assert.Len(t, configs, 2)
+	for _, view := range configs {
+		if view.Path == "/raw" {
+			assert.Nil(t, view.Config)
+			assert.Contains(t, view.Error, "unsupported type")
+		} else {
+			assert.Empty(t, view.Error)
+		}
+	}
+	assert.NoError(t, container.UnloadNamedComponents([]compcont.ComponentName{"raw"}, false))
+
+	var factories []string
+	assert.Equal(t, http.StatusOK, get(t, h, "GET", "/factories", &factories))
+	assert.Equal(t, []string{"db"}, factories)
+
+	var events []eventView
+	assert.Equal(t, http.StatusOK, get(t, h, "GET", "/events", &events))
+	assert.Equal(t, compcont.EventAfterCreate, events[0].Type)
+	assert.Equal(t, "/db", events[0].Path)
+
+	// 默认只读
+	assert.Equal(t, http.StatusForbidden, get(t, h, "POST", "/admin/restart?path=/db", nil))
+
+	admin := NewHandler(container, Options{EnableAdmin: true})
+	assert.Equal(t, http.StatusOK, get(t, admin, "POST", "/admin/restart?path=/db", nil))
+	assert.Equal(t, 2, *created)
+	assert.Equal(t, http.StatusNotFound, get(t, admin, "POST", "/admin/restart?path=/missing", nil))
+
+	// 引用组件重启其引用的组件
+	assert.NoError(t, container.LoadNamedComponents([]compcont.ComponentConfig{{Name: "primary", Refer: "/db"}}))
+	var restarted map[string]string
+	assert.Equal(t, http.StatusOK, get(t, admin, "POST", "/admin/restart?path=/primary", &restarted))
+	assert.Equal(t, "/db", restarted["path"])
+	assert.Equal(t, 3, *created)
+	assert.Equal(t, http.StatusNotImplemented, get(t, admin, "POST", "/admin/reload", nil))
+}
@@ -12,4 +12,12 @@ var (
 	ErrComponentTypeNotRegistered     = errors.New("component type not registered")
 	ErrComponentTypeAlreadyRegistered = errors.New("component type already registered")
 	ErrCircularDependency             = errors.New("circular dependency detected")
+	ErrComponentAmbiguous             = errors.New("component is ambiguous")
+	ErrComponentDisabled              = errors.New("component is disabled")
+	ErrComponentScopeRequired         = errors.New("scoped component must be resolved within a scope")
+	ErrComponentScopeMismatch         = errors.New("component scope mismatch")
+	ErrComponentHasDependents         = errors.New("component has dependents")
+	ErrReconcilePlanStale             = errors.New("reconcile plan is stale")
+	ErrComponentVetoed                = errors.New("component operation vetoed by hook")
+	ErrRestartIntensityExceeded       = errors.New("restart intensity exceeded")
 )
@@ -0,0 +1,223 @@
+package compcont
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+// 组件生命周期事件的类型
+type EventType string
+
+const (
+	EventBeforeCreate   EventType = "before_create"   // 组件实例创建前，订阅者返回错误可否决创建，修改Context.Config.Config可调整传给工厂的配置
+	EventAfterCreate    EventType = "after_create"    // 组件实例创建成功
+	EventCreateRetrying EventType = "create_retrying" // 组件实例创建失败，即将按重试策略重试
+	EventCreateFailed   EventType = "create_failed"   // 组件实例创建失败或被否决
+	EventBeforeDestroy  EventType = "before_destroy"  // 组件实例销毁前
+	EventAfterDestroy   EventType = "after_destroy"   // 组件实例销毁成功
+	EventDestroyFailed  EventType = "destroy_failed"  // 组件实例销毁失败
+	EventStarted        EventType = "started"         // 组件启动成功
+	EventStartFailed    EventType = "start_failed"    // 组件启动失败
+	EventStopped        EventType = "stopped"         // 组件停止成功
+	EventStopFailed     EventType = "stop_failed"     // 组件停止失败
+	EventDrained        EventType = "drained"         // 组件排空成功
+	EventDrainFailed    EventType = "drain_failed"    // 组件排空失败
+	EventRestarting     EventType = "restarting"      // 组件报告了致命错误，监督者即将重建组件
+	EventRestarted      EventType = "restarted"       // 监督者重建组件成功
+	EventRestartFailed  EventType = "restart_failed"  // 监督者重建组件失败
+	EventRestartGaveUp  EventType = "restart_gave_up" // 重建次数超过限制，监督者放弃重建并上报给父容器
+)
+
+// 是否为操作前的事件，这类事件不记录到最近事件中
+func (t EventType) before() bool {
+	return t == EventBeforeCreate || t == EventBeforeDestroy
+}
+
+// 组件生命周期事件
+type Event struct {
+	Type     EventType
+	Time     time.Time
+	Context  BuildContext  // 事件对应组件的上下文
+	Duration time.Duration // 事件对应操作的耗时
+	Err      error
+	Attempt  int // 创建事件对应的第几次尝试，重建事件对应的第几次重建
+}
+
+// 事件对应组件的绝对路径
+func (e Event) Path() string {
+	return formatPath(e.Context.GetAbsolutePath())
+}
+
+// 事件的订阅者。只有EventBeforeCreate的订阅者返回的错误会否决创建，其余事件订阅者的错误被忽略
+type EventHandler func(e *Event) error
+
+// 容器上的事件订阅者列表
+type eventHooks struct {
+	mu       sync.RWMutex
+	next     int
+	handlers []eventHandlerEntry
+}
+
+type eventHandlerEntry struct {
+	id      int
+	handler EventHandler
+}
+
+func (h *eventHooks) snapshot() []eventHandlerEntry {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return slices.Clone(h.handlers)
+}
+
+// Subscribe 订阅容器及其子容器中组件的生命周期事件，子容器的事件会逐级冒泡到根容器，
+// 先通知子容器的订阅者，同一容器内按订阅顺序通知。操作后的事件在容器释放锁后通知，订阅者可以在其中调用
+// Start、Stop、ApplyReconcile等方法；操作前的事件同步通知，订阅者不能调用这些方法。返回的函数用于取消订阅
+func (c *ComponentContainer) Subscribe(handler EventHandler) (unsubscribe func()) {
+	h := &c.hooks
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	id := h.next
+	h.next++
+	h.handlers = append(h.handlers, eventHandlerEntry{id: id, handler: handler})
+	return func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		h.handlers = slices.DeleteFunc(h.handlers, func(e eventHandlerEntry) bool { return e.id == id })
+	}
+}
+
+// 事件冒泡的上一级容器：作用域冒泡到所属容器，子容器冒泡到父容器
+func (c *ComponentContainer) upstream() *ComponentContainer {
+	if c.scopeOf != nil {
+		return c.scopeOf
+	}
+	parent, _ := c.parent.(*ComponentContainer)
+	return parent
+}
+
+// 发布事件：从当前容器开始逐级通知订阅者，并记录到最近事件中。
+// 操作前的事件同步通知，EventBeforeCreate在首个订阅者返回错误时停止通知并返回该错误，
+// 这类事件的订阅者可能在容器持有锁时被调用，不能调用Start、Stop、ApplyReconcile等方法。
+// 其余事件经由分发器通知，持有容器锁期间产生的事件会在锁释放后通知
+func (c *ComponentContainer) emit(e *Event) (err error) {
+	if e.Type.before() {
+		return c.notify(e)
+	}
+	c.events.record(*e)
+	logEvent(e)
+	c.metrics.observe(e)
+	event := *e
+	c.dispatcher.deliver(func() { c.notify(&event) })
+	return
+}
+
+// 逐级通知订阅者
+func (c *ComponentContainer) notify(e *Event) error {
+	for cc := c; cc != nil; cc = cc.upstream() {
+		for _, entry := range cc.hooks.snapshot() {
+			if hookErr := entry.handler(e); hookErr != nil && e.Type == EventBeforeCreate {
+				return fmt.Errorf("%w: %w", ErrComponentVetoed, hookErr)
+			}
+		}
+	}
+	return nil
+}
+
+// 事件分发器，同一棵容器树共享。持有Start、Stop、热更新与卸载的锁期间产生的事件先排队，
+// 全部锁释放后按产生的顺序通知，订阅者因此可以在回调中安全地调用这些方法
+type eventDispatcher struct {
+	mu       sync.Mutex
+	holds    int      // 正在持有容器锁的操作数量
+	flushing bool     // 是否正在通知排队的事件
+	queue    []func() // 排队的通知
+}
+
+// 进入持有容器锁的操作，需在加锁之前调用，并在解锁之后调用release
+func (d *eventDispatcher) hold() {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	d.holds++
+	d.mu.Unlock()
+}
+
+// 离开持有容器锁的操作，所有操作都已离开时通知排队的事件
+func (d *eventDispatcher) release() {
+	if d == nil {
+		return
+	}
+	d.mu.Lock()
+	d.holds--
+	d.mu.Unlock()
+	d.flush()
+}
+
+// 没有持有锁的操作且没有排队的事件时立即通知，否则排队
+func (d *eventDispatcher) deliver(fn func()) {
+	if d == nil {
+		fn()
+		return
+	}
+	d.mu.Lock()
+	if d.holds > 0 || d.flushing || len(d.queue) > 0 {
+		d.queue = append(d.queue, fn)
+		d.mu.Unlock()
+		return
+	}
+	d.mu.Unlock()
+	fn()
+}
+
+// 按顺序通知排队的事件，通知期间新排队的事件由同一个调用方继续通知
+func (d *eventDispatcher) flush() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.holds > 0 || d.flushing {
+		return
+	}
+	d.flushing = true
+	for len(d.queue) > 0 {
+		fn := d.queue[0]
+		d.queue = d.queue[1:]
+		d.mu.Unlock()
+		fn()
+		d.mu.Lock()
+	}
+	d.flushing = false
+}
+
+// 保留的最近事件数量
+const recentEventsLimit = 256
+
+// 最近事件的环形缓冲区，同一棵容器树共享
+type eventLog struct {
+	mu     sync.Mutex
+	events []Event
+	next   int
+}
+
+func (l *eventLog) record(e Event) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.events) < recentEventsLimit {
+		l.events = append(l.events, e)
+		return
+	}
+	l.events[l.next] = e
+	l.next = (l.next + 1) % recentEventsLimit
+}
+
+// 按时间顺序返回最近的事件
+func (l *eventLog) recent() []Event {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return slices.Concat(l.events[l.next:], l.events[:l.next])
+}
+
+// RecentEvents 按时间顺序返回容器树中最近发生的组件生命周期事件
+func (c *ComponentContainer) RecentEvents() []Event {
+	return c.events.recent()
+}
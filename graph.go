@@ -0,0 +1,261 @@
+package compcont
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// 依赖图中边的类型
+type GraphEdgeKind string
+
+const (
+	GraphEdgeDep      GraphEdgeKind = "dep"      // 组件依赖，来自Deps
+	GraphEdgeRefer    GraphEdgeKind = "refer"    // 组件引用，来自Refer
+	GraphEdgeContains GraphEdgeKind = "contains" // 容器组件包含的子组件
+	GraphEdgeOwns     GraphEdgeKind = "owns"     // 组件构造时加载的匿名组件
+)
+
+// 依赖图的JSON格式版本
+const GraphSchemaVersion = 1
+
+type GraphNode struct {
+	Path      string          `json:"path"`            // 组件的绝对路径，作为节点的唯一标识
+	Name      ComponentName   `json:"name"`            // 组件名称，匿名组件为空
+	Type      ComponentTypeID `json:"type"`            // 组件类型，引用组件为空
+	State     ComponentState  `json:"state,omitempty"` // 组件状态，根据配置生成的依赖图中为空
+	Container bool            `json:"container"`       // 组件是否为容器
+}
+
+type GraphEdge struct {
+	From string        `json:"from"`
+	To   string        `json:"to"`
+	Kind GraphEdgeKind `json:"kind"`
+}
+
+// 容器树的依赖图
+type Graph struct {
+	SchemaVersion int         `json:"schema_version"`
+	Nodes         []GraphNode `json:"nodes"` // 按路径排序
+	Edges         []GraphEdge `json:"edges"` // 按起点、终点、类型排序
+}
+
+// 导出依赖图时的过滤条件
+type GraphOptions struct {
+	Root string // 只导出该路径及其子树中的组件，为空则导出整棵容器树
+}
+
+// 遍历容器及其所有子容器，构建依赖图，节点路径为组件在整棵容器树中的绝对路径
+func BuildGraph(container IComponentContainer, opt GraphOptions) (graph Graph, err error) {
+	root := strings.TrimSuffix(opt.Root, "/")
+	inSubtree := func(path string) bool {
+		return root == "" || path == root || strings.HasPrefix(path, root+"/")
+	}
+
+	graph.SchemaVersion = GraphSchemaVersion
+	nodes := make(set[string])
+	err = Walk(container, func(node WalkNode) error {
+		if !inSubtree(node.Path) {
+			return nil
+		}
+		_, isContainer := node.Component.Instance.(IComponentContainer)
+		graph.Nodes = append(graph.Nodes, GraphNode{
+			Path:      node.Path,
+			Name:      node.Name,
+			Type:      node.Config.Type,
+			State:     node.State,
+			Container: isContainer,
+		})
+		nodes[node.Path] = struct{}{}
+
+		dir := node.Path[:strings.LastIndex(node.Path, "/")]
+		if node.Owner != "" {
+			graph.Edges = append(graph.Edges, GraphEdge{From: node.Owner, To: node.Path, Kind: GraphEdgeOwns})
+		} else if dir != "" {
+			graph.Edges = append(graph.Edges, GraphEdge{From: dir, To: node.Path, Kind: GraphEdgeContains})
+		}
+		depBase := dir
+		if node.Owner != "" && node.Component.BuildContext.Container != nil { // 匿名组件的依赖位于其所在的容器中，而非所属组件的路径下
+			depBase = containerPath(node.Component.BuildContext.Container)
+		}
+		for _, dep := range node.Config.Deps {
+			dep, _ := parseDep(dep)
+			if !slices.Contains(node.Component.BuildContext.MissingDeps, dep) {
+				graph.Edges = append(graph.Edges, GraphEdge{From: node.Path, To: depBase + "/" + dep.String(), Kind: GraphEdgeDep})
+			}
+		}
+		if node.Config.Refer != "" {
+			// 引用组件的BuildContext即为被引用组件的上下文
+			target := node.Component.BuildContext
+			if target.Mount != nil {
+				graph.Edges = append(graph.Edges, GraphEdge{From: node.Path, To: formatPath(target.GetAbsolutePath()), Kind: GraphEdgeRefer})
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return
+	}
+
+	// 只保留两端都在子树中的边
+	graph.Edges = slices.DeleteFunc(graph.Edges, func(e GraphEdge) bool {
+		_, from := nodes[e.From]
+		_, to := nodes[e.To]
+		return !from || !to
+	})
+	slices.SortFunc(graph.Nodes, func(a, b GraphNode) int { return cmp.Compare(a.Path, b.Path) })
+	slices.SortFunc(graph.Edges, func(a, b GraphEdge) int {
+		return cmp.Or(cmp.Compare(a.From, b.From), cmp.Compare(a.To, b.To), cmp.Compare(a.Kind, b.Kind))
+	})
+	graph.Edges = slices.Compact(graph.Edges)
+	return
+}
+
+// PlanGraph 根据一批具名组件的声明配置生成依赖图，不会创建组件实例，也不会修改容器。
+// 被禁用的组件不出现在图中，引用只能静态解析到同一批配置中的组件，指向子容器内部的引用会被忽略
+func (c *ComponentContainer) PlanGraph(configs []ComponentConfig) (graph Graph, err error) {
+	configMap, err := newConfigMap(configs)
+	if err != nil {
+		return
+	}
+	if _, err = c.conditionEnv().filter(configMap); err != nil {
+		return
+	}
+	inferGroupDeps(c.factoryRegistry, configMap, nil)
+	graph.SchemaVersion = GraphSchemaVersion
+	base := containerPath(c)
+	nodes := make(set[string])
+	for name, cfg := range configMap {
+		path := base + "/" + name.String()
+		graph.Nodes = append(graph.Nodes, GraphNode{Path: path, Name: name, Type: cfg.Type})
+		nodes[path] = struct{}{}
+		for _, dep := range cfg.Deps {
+			dep, _ := parseDep(dep)
+			graph.Edges = append(graph.Edges, GraphEdge{From: path, To: base + "/" + dep.String(), Kind: GraphEdgeDep})
+		}
+		if cfg.Type == "" && cfg.Refer != "" {
+			if target, ok := staticReferPath(base, cfg.Refer); ok {
+				graph.Edges = append(graph.Edges, GraphEdge{From: path, To: target, Kind: GraphEdgeRefer})
+			}
+		}
+	}
+	graph.Edges = slices.DeleteFunc(graph.Edges, func(e GraphEdge) bool {
+		_, to := nodes[e.To]
+		return !to
+	})
+	slices.SortFunc(graph.Nodes, func(a, b GraphNode) int { return cmp.Compare(a.Path, b.Path) })
+	slices.SortFunc(graph.Edges, func(a, b GraphEdge) int {
+		return cmp.Or(cmp.Compare(a.From, b.From), cmp.Compare(a.To, b.To), cmp.Compare(a.Kind, b.Kind))
+	})
+	graph.Edges = slices.Compact(graph.Edges)
+	return
+}
+
+// 不经过容器查找，将引用路径解析为绝对路径，base为引用所在容器的绝对路径
+func staticReferPath(base, refer string) (path string, ok bool) {
+	parts, absolute, err := parseReferPath(refer)
+	if err != nil {
+		return
+	}
+	var resolved []ComponentName
+	if !absolute {
+		resolved, _, _ = parseReferPath(base)
+	}
+	for _, part := range parts {
+		switch part {
+		case ".":
+		case "..":
+			if len(resolved) == 0 {
+				return
+			}
+			resolved = resolved[:len(resolved)-1]
+		default:
+			resolved = append(resolved, part)
+		}
+	}
+	return formatPath(resolved), len(resolved) > 0
+}
+
+// 将组件路径格式化为/a/b的形式
+func formatPath(path []ComponentName) string {
+	var b strings.Builder
+	for _, name := range path {
+		b.WriteString("/")
+		b.WriteString(name.String())
+	}
+	return b.String()
+}
+
+// 以JSON格式输出依赖图
+func (g Graph) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(g)
+}
+
+// 以Graphviz DOT格式输出依赖图
+func (g Graph) WriteDOT(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("digraph compcont {\n\trankdir=LR;\n\tnode [shape=box];\n")
+	for _, n := range g.Nodes {
+		shape := "box"
+		if n.Container {
+			shape = "box3d"
+		}
+		fmt.Fprintf(&b, "\t%q [label=%q, shape=%s];\n", n.Path, nodeLabel(n), shape)
+	}
+	for _, e := range g.Edges {
+		style := map[GraphEdgeKind]string{
+			GraphEdgeDep:      "solid",
+			GraphEdgeRefer:    "dashed",
+			GraphEdgeContains: "dotted",
+			GraphEdgeOwns:     "dotted",
+		}[e.Kind]
+		fmt.Fprintf(&b, "\t%q -> %q [label=%q, style=%s];\n", e.From, e.To, e.Kind, style)
+	}
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// 以Mermaid flowchart格式输出依赖图
+func (g Graph) WriteMermaid(w io.Writer) error {
+	ids := make(map[string]string, len(g.Nodes))
+	var b strings.Builder
+	b.WriteString("flowchart LR\n")
+	for i, n := range g.Nodes {
+		ids[n.Path] = fmt.Sprintf("n%d", i)
+		label := strings.ReplaceAll(nodeLabel(n), `"`, "#quot;")
+		label = strings.ReplaceAll(label, "\n", "<br/>")
+		if n.Container {
+			fmt.Fprintf(&b, "\t%s[[\"%s\"]]\n", ids[n.Path], label)
+		} else {
+			fmt.Fprintf(&b, "\t%s[\"%s\"]\n", ids[n.Path], label)
+		}
+	}
+	for _, e := range g.Edges {
+		arrow := map[GraphEdgeKind]string{
+			GraphEdgeDep:      "-->",
+			GraphEdgeRefer:    "-.->",
+			GraphEdgeContains: "---",
+			GraphEdgeOwns:     "---",
+		}[e.Kind]
+		fmt.Fprintf(&b, "\t%s %s|%s| %s\n", ids[e.From], arrow, e.Kind, ids[e.To])
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func nodeLabel(n GraphNode) string {
+	typ := n.Type.String()
+	if typ == "" {
+		typ = "refer"
+	}
+	if n.State == "" {
+		return fmt.Sprintf("%s\n%s", n.Path, typ)
+	}
+	return fmt.Sprintf("%s\n%s (%s)", n.Path, typ, n.State)
+}
@@ -0,0 +1,73 @@
+package compcont
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type containerConfig struct {
+	Components []ComponentConfig `ccf:"components"`
+}
+
+// 构造子容器的工厂
+func newContainerFactory(registry IFactoryRegistry) IComponentFactory {
+	return &TypedSimpleComponentFactory[containerConfig, IComponentContainer]{
+		TypeID: "container",
+		CreateInstanceFunc: func(ctx BuildContext, config containerConfig) (instance IComponentContainer, err error) {
+			instance = NewComponentContainer(WithFactoryRegistry(registry), WithParentContainer(ctx.Container), WithContext(ctx))
+			err = instance.LoadNamedComponents(config.Components)
+			return
+		},
+	}
+}
+
+func TestBuildGraph(t *testing.T) {
+	registry := NewFactoryRegistry()
+	MustRegister(registry, factoryA)
+	MustRegister(registry, factoryOwningB)
+	MustRegister(registry, newContainerFactory(registry))
+	container := NewComponentContainer(WithFactoryRegistry(registry))
+	err := container.LoadNamedComponents([]ComponentConfig{
+		{Name: "infra", Type: "container", Config: containerConfig{Components: []ComponentConfig{
+			{Name: "db", Type: "a"},
+		}}},
+		{Name: "db", Refer: "/infra/db", Deps: []ComponentName{"infra"}},
+		{Name: "svc", Type: "b", Deps: []ComponentName{"db"}, Config: ConfigB{
+			InnerA: TypedComponentConfig[ConfigA, IComponentA]{Type: "a", Deps: []ComponentName{"infra"}},
+		}},
+		{Name: "cache", Type: "a", Lazy: true},
+	})
+	assert.NoError(t, err)
+
+	graph, err := BuildGraph(container, GraphOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, []GraphNode{
+		{Path: "/cache", Name: "cache", Type: "a", State: ComponentStatePending},
+		{Path: "/db", Name: "db", State: ComponentStateReady},
+		{Path: "/infra", Name: "infra", Type: "container", State: ComponentStateReady, Container: true},
+		{Path: "/infra/db", Name: "db", Type: "a", State: ComponentStateReady},
+		{Path: "/svc", Name: "svc", Type: "b", State: ComponentStateReady},
+		{Path: "/svc/#0", Type: "a", State: ComponentStateReady},
+	}, graph.Nodes)
+	assert.Equal(t, []GraphEdge{
+		{From: "/db", To: "/infra", Kind: GraphEdgeDep},
+		{From: "/db", To: "/infra/db", Kind: GraphEdgeRefer},
+		{From: "/infra", To: "/infra/db", Kind: GraphEdgeContains},
+		{From: "/svc", To: "/db", Kind: GraphEdgeDep},
+		{From: "/svc", To: "/svc/#0", Kind: GraphEdgeOwns},
+		{From: "/svc/#0", To: "/infra", Kind: GraphEdgeDep}, // 匿名组件的依赖从其所在的容器解析
+	}, graph.Edges)
+
+	subtree, err := BuildGraph(container, GraphOptions{Root: "/infra"})
+	assert.NoError(t, err)
+	assert.Len(t, subtree.Nodes, 2)
+	assert.Equal(t, []GraphEdge{{From: "/infra", To: "/infra/db", Kind: GraphEdgeContains}}, subtree.Edges)
+
+	var dot, mermaid bytes.Buffer
+	assert.NoError(t, subtree.WriteDOT(&dot))
+	assert.Contains(t, dot.String(), `"/infra" -> "/infra/db" [label="contains", style=dotted];`)
+	assert.NoError(t, subtree.WriteMermaid(&mermaid))
+	assert.Contains(t, mermaid.String(), "n0 ---|contains| n1")
+}
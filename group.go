@@ -0,0 +1,115 @@
+package compcont
+
+import (
+	"reflect"
+	"slices"
+)
+
+// 组件配置中的组件组，用于在加载前找出组件对组成员的隐式依赖
+type iComponentGroup interface {
+	groupSelector() string
+}
+
+func (g ComponentGroup[Instance]) groupSelector() string {
+	return g.Selector
+}
+
+// 组件工厂可实现该接口，按自身的配置解码规则找出组件配置中组件组的选择器
+type iGroupSelectorsProvider interface {
+	groupSelectors(config any) []string
+}
+
+// 找出组件配置中所有组件组的选择器，工厂未实现iGroupSelectorsProvider时直接遍历配置的值
+func groupSelectors(registry IFactoryRegistry, cfg ComponentConfig) []string {
+	if cfg.Type != "" {
+		if factory, err := registry.GetFactory(cfg.Type); err == nil {
+			if provider, ok := factory.(iGroupSelectorsProvider); ok {
+				return provider.groupSelectors(cfg.Config)
+			}
+		}
+	}
+	return findGroupSelectors(reflect.ValueOf(cfg.Config), make(set[uintptr]))
+}
+
+var componentGroupType = reflect.TypeFor[iComponentGroup]()
+
+// 递归遍历配置的值，visited用于防止指针成环时无限递归
+func findGroupSelectors(v reflect.Value, visited set[uintptr]) (selectors []string) {
+	if !v.IsValid() {
+		return
+	}
+	if v.Type().Implements(componentGroupType) && v.CanInterface() {
+		return []string{v.Interface().(iComponentGroup).groupSelector()}
+	}
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return
+		}
+		if _, ok := visited[v.Pointer()]; ok {
+			return
+		}
+		visited[v.Pointer()] = struct{}{}
+		return findGroupSelectors(v.Elem(), visited)
+	case reflect.Interface:
+		return findGroupSelectors(v.Elem(), visited)
+	case reflect.Struct:
+		for i := range v.NumField() {
+			if v.Type().Field(i).IsExported() {
+				selectors = append(selectors, findGroupSelectors(v.Field(i), visited)...)
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := range v.Len() {
+			selectors = append(selectors, findGroupSelectors(v.Index(i), visited)...)
+		}
+	case reflect.Map:
+		for iter := v.MapRange(); iter.Next(); {
+			selectors = append(selectors, findGroupSelectors(iter.Value(), visited)...)
+		}
+	}
+	return
+}
+
+// 将组件组选择器匹配的单例组件作为可选依赖补充到组件的Deps中，使组成员先于组件构建、后于组件销毁。
+// 候选的组成员为configMap与existing中的组件，无法解析的选择器留到组件创建时报告
+func inferGroupDeps(registry IFactoryRegistry, configMap, existing map[ComponentName]ComponentConfig) {
+	for name, cfg := range configMap {
+		var members []ComponentName
+		for _, s := range groupSelectors(registry, cfg) {
+			selector, err := ParseSelector(s)
+			if err != nil {
+				continue
+			}
+			match := func(member ComponentName, memberCfg ComponentConfig) {
+				if member != name && memberCfg.Scope.isSingleton() && selector.Matches(memberCfg.Labels) {
+					members = append(members, member)
+				}
+			}
+			for member, memberCfg := range configMap {
+				match(member, memberCfg)
+			}
+			for member, memberCfg := range existing {
+				if _, ok := configMap[member]; !ok {
+					match(member, memberCfg)
+				}
+			}
+		}
+		slices.Sort(members)
+		declared := make(set[ComponentName])
+		for _, dep := range cfg.Deps {
+			target, _ := parseDep(dep)
+			declared[target] = struct{}{}
+		}
+		deps := slices.Clone(cfg.Deps)
+		for _, member := range slices.Compact(members) {
+			if _, ok := declared[member]; !ok {
+				deps = append(deps, member+"?")
+			}
+		}
+		if len(deps) > len(cfg.Deps) {
+			cfg.Deps = deps
+			configMap[name] = cfg
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package compcont
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// 支持健康检查的组件实例可实现该接口
+type IHealthChecker interface {
+	HealthCheck(ctx context.Context) error
+}
+
+// 组件的健康检查配置
+type HealthConfig struct {
+	Critical *bool `json:"critical" yaml:"critical"` // 是否为关键组件，不填为关键组件。非关键组件不健康时整体状态为degraded
+	Liveness bool  `json:"liveness" yaml:"liveness"` // 是否参与存活检查，所有实现了IHealthChecker的组件都参与就绪检查
+}
+
+// 是否为关键组件
+func (c HealthConfig) IsCritical() bool {
+	return c.Critical == nil || *c.Critical
+}
+
+// 健康检查的类型
+type HealthProbe string
+
+const (
+	ProbeLiveness  HealthProbe = "liveness"  // 存活检查，失败意味着进程需要被重启
+	ProbeReadiness HealthProbe = "readiness" // 就绪检查，失败意味着暂时不能接收流量
+)
+
+// 健康状态
+type HealthStatus string
+
+const (
+	HealthStatusUp       HealthStatus = "up"
+	HealthStatusDegraded HealthStatus = "degraded" // 只有非关键组件不健康
+	HealthStatusDown     HealthStatus = "down"     // 存在不健康的关键组件
+)
+
+// 单个组件的健康检查结果
+type ComponentHealth struct {
+	Path     string          `json:"path"`
+	Type     ComponentTypeID `json:"type"`
+	Critical bool            `json:"critical"`
+	Status   HealthStatus    `json:"status"`
+	Error    string          `json:"error,omitempty"`
+	Duration time.Duration   `json:"duration"`
+}
+
+// 整个容器树的健康检查结果
+type HealthReport struct {
+	Probe      HealthProbe       `json:"probe"`
+	Status     HealthStatus      `json:"status"`
+	Components []ComponentHealth `json:"components"` // 按遍历顺序排列
+}
+
+// 健康检查的选项
+type HealthCheckOptions struct {
+	Timeout     time.Duration // 单个组件检查的超时时间，不填为5秒
+	Concurrency int           // 同时进行检查的组件数，不填为8
+}
+
+// CheckHealth 并发地检查容器树中所有实现了IHealthChecker的已创建组件，引用组件由被引用的组件检查，不重复检查
+func CheckHealth(ctx context.Context, container IComponentContainer, probe HealthProbe, opt HealthCheckOptions) (report HealthReport, err error) {
+	if opt.Timeout <= 0 {
+		opt.Timeout = 5 * time.Second
+	}
+	if opt.Concurrency <= 0 {
+		opt.Concurrency = 8
+	}
+
+	type target struct {
+		checker IHealthChecker
+		config  HealthConfig
+	}
+	var targets []target
+	err = Walk(container, func(node WalkNode) error {
+		checker, ok := node.Component.Instance.(IHealthChecker)
+		if !ok || node.State != ComponentStateReady || node.Config.Refer != "" {
+			return nil
+		}
+		cfg := node.Config.Health
+		if probe == ProbeLiveness && !cfg.Liveness {
+			return nil
+		}
+		targets = append(targets, target{checker: checker, config: cfg})
+		report.Components = append(report.Components, ComponentHealth{
+			Path:     node.Path,
+			Type:     node.Component.BuildContext.Config.Type,
+			Critical: cfg.IsCritical(),
+		})
+		return nil
+	})
+	if err != nil {
+		return
+	}
+
+	sem := make(chan struct{}, opt.Concurrency)
+	var wg sync.WaitGroup
+	for i, t := range targets {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			start := time.Now()
+			checkErr := checkWithTimeout(ctx, t.checker, opt.Timeout)
+			result := &report.Components[i]
+			result.Duration = time.Since(start)
+			result.Status = HealthStatusUp
+			if checkErr != nil {
+				result.Status = HealthStatusDown
+				result.Error = checkErr.Error()
+			}
+		}()
+	}
+	wg.Wait()
+
+	report.Probe = probe
+	report.Status = HealthStatusUp
+	for _, result := range report.Components {
+		switch {
+		case result.Status == HealthStatusUp:
+		case result.Critical:
+			report.Status = HealthStatusDown
+		case report.Status == HealthStatusUp:
+			report.Status = HealthStatusDegraded
+		}
+	}
+	return
+}
+
+// 带超时地执行一次健康检查，检查未在超时内返回时视为失败
+func checkWithTimeout(ctx context.Context, checker IHealthChecker, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				done <- fmt.Errorf("health check panicked: %v", r)
+			}
+		}()
+		done <- checker.HealthCheck(ctx)
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return fmt.Errorf("health check timed out after %s", timeout)
+		}
+		return ctx.Err()
+	}
+}
+
+// NewHealthHandler 创建提供健康检查结果的http.Handler，整体状态为down时返回503，否则返回200，响应体为JSON格式的HealthReport。
+// 通常分别以ProbeLiveness与ProbeReadiness挂载到/livez与/readyz
+func NewHealthHandler(container IComponentContainer, probe HealthProbe, opt HealthCheckOptions) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		report, err := CheckHealth(r.Context(), container, probe, opt)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if report.Status == HealthStatusDown {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		json.NewEncoder(w).Encode(report)
+	})
+}
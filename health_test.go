@@ -0,0 +1,79 @@
+package compcont
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type healthConfig struct {
+	Fail  bool
+	Sleep time.Duration
+}
+
+type healthChecker healthConfig
+
+func (h healthChecker) HealthCheck(ctx context.Context) error {
+	select {
+	case <-time.After(h.Sleep):
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	if h.Fail {
+		return errors.New("connection refused")
+	}
+	return nil
+}
+
+func TestCheckHealth(t *testing.T) {
+	registry := NewFactoryRegistry()
+	MustRegister(registry, &TypedSimpleComponentFactory[healthConfig, healthChecker]{
+		TypeID: "checker",
+		CreateInstanceFunc: func(ctx BuildContext, config healthConfig) (instance healthChecker, err error) {
+			return healthChecker(config), nil
+		},
+	})
+	MustRegister(registry, newContainerFactory(registry))
+	nonCritical := false
+	container := NewComponentContainer(WithFactoryRegistry(registry))
+	err := container.LoadNamedComponents([]ComponentConfig{
+		{Name: "infra", Type: "container", Config: containerConfig{Components: []ComponentConfig{
+			{Name: "db", Type: "checker", Health: HealthConfig{Liveness: true}},
+		}}},
+		{Name: "db", Refer: "/infra/db", Deps: []ComponentName{"infra"}},
+		{Name: "cache", Type: "checker", Config: healthConfig{Fail: true}, Health: HealthConfig{Critical: &nonCritical}},
+		{Name: "lazy", Type: "checker", Lazy: true, Config: healthConfig{Fail: true}},
+	})
+	assert.NoError(t, err)
+
+	// 非关键组件不健康时为degraded，未创建的懒加载组件与重复引用的实例不参与检查
+	ctx := context.Background()
+	report, err := CheckHealth(ctx, container, ProbeReadiness, HealthCheckOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, HealthStatusDegraded, report.Status)
+	assert.Len(t, report.Components, 2)
+	i := slices.IndexFunc(report.Components, func(h ComponentHealth) bool { return h.Path == "/cache" })
+	assert.Equal(t, ComponentHealth{Path: "/cache", Type: "checker", Status: HealthStatusDown, Error: "connection refused", Duration: report.Components[i].Duration}, report.Components[i])
+
+	report, err = CheckHealth(ctx, container, ProbeLiveness, HealthCheckOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, HealthStatusUp, report.Status)
+	assert.Equal(t, "/infra/db", report.Components[0].Path)
+
+	// 关键组件检查超时
+	err = container.LoadNamedComponents([]ComponentConfig{
+		{Name: "broker", Type: "checker", Config: healthConfig{Sleep: time.Second}},
+	})
+	assert.NoError(t, err)
+	handler := NewHealthHandler(container, ProbeReadiness, HealthCheckOptions{Timeout: 20 * time.Millisecond})
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Contains(t, rec.Body.String(), "timed out")
+}
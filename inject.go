@@ -0,0 +1,214 @@
+package compcont
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// 构造函数组件的类型，由容器内置处理，不会注册到组件工厂注册器中
+const ProviderComponentType ComponentTypeID = "compcont.provider"
+
+var errorType = reflect.TypeFor[error]()
+
+// 由构造函数提供的具名组件
+type Provider struct {
+	Name        ComponentName   // 组件名称
+	Constructor any             // 构造函数，形如func(db *sql.DB, log *slog.Logger) (*Repo, error)，error返回值可省略
+	Params      []ComponentName // 按位置指定注入参数的组件名称，未指定或为空字符串的参数按类型注入
+}
+
+// 构造函数组件的配置
+type providerSpec struct {
+	fn   reflect.Value
+	args []ComponentName // 每个参数对应注入的组件名称
+}
+
+// Provide 将一批构造函数注册为具名组件，参数按名称或类型从容器中已加载的组件及同批次的构造函数中解析，
+// 构造顺序与普通组件一样由依赖关系的拓扑排序决定
+func Provide(container IComponentContainer, providers ...Provider) (err error) {
+	// 同批次构造函数的返回值类型，用于按类型解析参数
+	outputs := make(map[ComponentName]reflect.Type)
+	for _, p := range providers {
+		fnType := reflect.TypeOf(p.Constructor)
+		if err = validateFunc(fnType); err != nil {
+			err = fmt.Errorf("provider %s: %w", p.Name, err)
+			return
+		}
+		if fnType.NumOut() == 0 || fnType.Out(0) == errorType {
+			err = fmt.Errorf("%w, provider %s: constructor must return a component instance", ErrComponentConfigInvalid, p.Name)
+			return
+		}
+		outputs[p.Name] = fnType.Out(0)
+	}
+
+	configs := make([]ComponentConfig, 0, len(providers))
+	for _, p := range providers {
+		spec := &providerSpec{fn: reflect.ValueOf(p.Constructor)}
+		spec.args, err = resolveParams(container, spec.fn.Type(), p.Params, outputs, p.Name)
+		if err != nil {
+			err = fmt.Errorf("provider %s: %w", p.Name, err)
+			return
+		}
+		configs = append(configs, ComponentConfig{
+			Name:   p.Name,
+			Type:   ProviderComponentType,
+			Deps:   spec.args,
+			Config: spec,
+		})
+	}
+	return container.LoadNamedComponents(configs)
+}
+
+// Invoke 从容器中按名称或类型解析fn的参数并调用fn，fn的最后一个返回值为error时作为调用结果返回
+func Invoke(container IComponentContainer, fn any, params ...ComponentName) (err error) {
+	fnType := reflect.TypeOf(fn)
+	if err = validateFunc(fnType); err != nil {
+		return
+	}
+	args, err := resolveParams(container, fnType, params, nil, "")
+	if err != nil {
+		return
+	}
+	results, components, err := callWithComponents(container, reflect.ValueOf(fn), args)
+	// 为本次调用创建的瞬态组件实例在调用结束后销毁
+	if destroyer, ok := container.(interface{ DestroyComponent(Component) error }); ok {
+		for _, component := range components {
+			if component.BuildContext.Config.Scope == ScopeTransient {
+				err = errors.Join(err, destroyer.DestroyComponent(component))
+			}
+		}
+	}
+	if err != nil {
+		return
+	}
+	if n := len(results); n > 0 && fnType.Out(n-1) == errorType && !results[n-1].IsNil() {
+		err = results[n-1].Interface().(error)
+	}
+	return
+}
+
+func validateFunc(fnType reflect.Type) error {
+	if fnType == nil || fnType.Kind() != reflect.Func {
+		return fmt.Errorf("%w, expected a function but got %v", ErrComponentConfigInvalid, fnType)
+	}
+	if fnType.IsVariadic() {
+		return fmt.Errorf("%w, variadic function %v is not supported", ErrComponentConfigInvalid, fnType)
+	}
+	if fnType.NumOut() > 2 || fnType.NumOut() == 2 && fnType.Out(1) != errorType {
+		return fmt.Errorf("%w, function %v must return (T), (T, error) or (error)", ErrComponentConfigInvalid, fnType)
+	}
+	return nil
+}
+
+// 解析函数每个参数对应注入的组件名称，按类型注入时的匹配规则与ResolveComponent一致
+func resolveParams(container IComponentContainer, fnType reflect.Type, params []ComponentName, outputs map[ComponentName]reflect.Type, self ComponentName) (args []ComponentName, err error) {
+	if len(params) > fnType.NumIn() {
+		err = fmt.Errorf("%w, %d params specified but function %v has %d", ErrComponentConfigInvalid, len(params), fnType, fnType.NumIn())
+		return
+	}
+	for i := range fnType.NumIn() {
+		if i < len(params) && params[i] != "" {
+			args = append(args, params[i])
+			continue
+		}
+		paramType := fnType.In(i)
+		var candidates []ComponentName
+		for _, name := range slices.Sorted(maps.Keys(outputs)) {
+			if name != self && outputs[name].AssignableTo(paramType) {
+				candidates = append(candidates, name)
+			}
+		}
+		for _, name := range container.LoadedComponentNames() {
+			if _, ok := outputs[name]; ok {
+				continue
+			}
+			// 与同批次的构造函数一样，尚未创建实例的组件按声明的实例类型匹配，不会触发创建
+			info, inspectErr := InspectComponent(container, name)
+			if inspectErr != nil {
+				continue
+			}
+			if t := componentType(container.FactoryRegistry(), info); t != nil && t.AssignableTo(paramType) {
+				candidates = append(candidates, name)
+			}
+		}
+		switch len(candidates) {
+		case 0:
+			err = fmt.Errorf("%w, no component for param #%d of type %v", ErrComponentDependencyNotFound, i, paramType)
+			return
+		case 1:
+			args = append(args, candidates[0])
+		default:
+			names := make([]string, 0, len(candidates))
+			for _, name := range candidates {
+				names = append(names, name.String())
+			}
+			err = fmt.Errorf("%w, param #%d of type %v, candidates: %s", ErrComponentAmbiguous, i, paramType, strings.Join(names, ", "))
+			return
+		}
+	}
+	return
+}
+
+// 从容器中获取参数对应的组件并调用函数，同时返回已获取的组件
+func callWithComponents(container IComponentContainer, fn reflect.Value, args []ComponentName) (results []reflect.Value, components []Component, err error) {
+	fnType := fn.Type()
+	in := make([]reflect.Value, 0, len(args))
+	for i, name := range args {
+		var component Component
+		component, err = container.GetComponent(name)
+		if err != nil {
+			return
+		}
+		components = append(components, component)
+		paramType := fnType.In(i)
+		if component.Instance == nil {
+			in = append(in, reflect.Zero(paramType))
+			continue
+		}
+		v := reflect.ValueOf(component.Instance)
+		if !v.Type().AssignableTo(paramType) {
+			err = fmt.Errorf("%w, name: %s, expected instance type %v, but got %v", ErrComponentTypeMismatch, name, paramType, v.Type())
+			return
+		}
+		in = append(in, v)
+	}
+	return fn.Call(in), components, nil
+}
+
+// 构造函数组件的工厂，由容器直接使用，不注册到共享的组件工厂注册器中
+type providerFactory struct{}
+
+func (providerFactory) Type() ComponentTypeID {
+	return ProviderComponentType
+}
+
+func (providerFactory) CreateInstance(ctx BuildContext, config any) (instance any, err error) {
+	spec, ok := config.(*providerSpec)
+	if !ok {
+		err = fmt.Errorf("%w, unexpected provider config type %T", ErrComponentConfigInvalid, config)
+		return
+	}
+	results, components, err := callWithComponents(ctx.Container, spec.fn, spec.args)
+	// 为构造函数创建的瞬态组件实例归属于构造出的组件，随其一同销毁，构造失败时立即销毁
+	for _, component := range components {
+		if component.BuildContext.Config.Scope == ScopeTransient {
+			ctx.own(component)
+		}
+	}
+	if err != nil {
+		return
+	}
+	if len(results) == 2 && !results[1].IsNil() {
+		err = results[1].Interface().(error)
+		return
+	}
+	return results[0].Interface(), nil
+}
+
+func (providerFactory) DestroyInstance(ctx BuildContext, instance any) (err error) {
+	return
+}
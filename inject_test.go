@@ -0,0 +1,125 @@
+package compcont
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type injectDB struct{ dsn string }
+
+type injectLogger interface{ Prefix() string }
+
+type injectLoggerImpl struct{ prefix string }
+
+func (l *injectLoggerImpl) Prefix() string { return l.prefix }
+
+type injectRepo struct {
+	db  *injectDB
+	log injectLogger
+}
+
+func TestProvideInvoke(t *testing.T) {
+	container := NewComponentContainer(WithFactoryRegistry(NewFactoryRegistry()))
+
+	err := Provide(container,
+		Provider{Name: "repo", Constructor: func(db *injectDB, log injectLogger) (*injectRepo, error) {
+			return &injectRepo{db: db, log: log}, nil
+		}},
+		Provider{Name: "db", Constructor: func() *injectDB { return &injectDB{dsn: "mem"} }},
+		Provider{Name: "log", Constructor: func() *injectLoggerImpl { return &injectLoggerImpl{prefix: "app"} }},
+	)
+	assert.NoError(t, err)
+	names := container.LoadedComponentNames()
+	assert.ElementsMatch(t, []ComponentName{"db", "log"}, names[:2])
+	assert.Equal(t, ComponentName("repo"), names[2])
+
+	var repo *injectRepo
+	assert.NoError(t, Invoke(container, func(r *injectRepo) { repo = r }))
+	assert.Equal(t, "mem", repo.db.dsn)
+	assert.Equal(t, "app", repo.log.Prefix())
+
+	// 按名称注入
+	assert.NoError(t, Provide(container, Provider{Name: "log2", Constructor: func() injectLogger { return &injectLoggerImpl{prefix: "audit"} }}))
+	assert.NoError(t, Invoke(container, func(log injectLogger) {
+		assert.Equal(t, "audit", log.Prefix())
+	}, "log2"))
+
+	// 按类型注入时存在多个候选
+	err = Invoke(container, func(log injectLogger) {})
+	assert.ErrorIs(t, err, ErrComponentAmbiguous)
+
+	// 依赖不存在
+	err = Provide(container, Provider{Name: "svc", Constructor: func(s string) int { return 0 }})
+	assert.ErrorIs(t, err, ErrComponentDependencyNotFound)
+
+	// 循环依赖
+	type x struct{}
+	type y struct{}
+	err = Provide(container,
+		Provider{Name: "x", Constructor: func(*y) *x { return nil }},
+		Provider{Name: "y", Constructor: func(*x) *y { return nil }},
+	)
+	assert.ErrorIs(t, err, ErrCircularDependency)
+
+	// 调用结果的错误
+	assert.EqualError(t, Invoke(container, func() error { return errors.New("boom") }), "boom")
+}
+
+func TestInjectWithoutCreating(t *testing.T) {
+	var created int
+	registry := NewFactoryRegistry()
+	MustRegister(registry, &TypedSimpleComponentFactory[any, *injectDB]{
+		TypeID: "db",
+		CreateInstanceFunc: func(ctx BuildContext, config any) (instance *injectDB, err error) {
+			created++
+			return &injectDB{dsn: "lazy"}, nil
+		},
+	})
+	container := NewComponentContainer(WithFactoryRegistry(registry))
+	assert.NoError(t, container.LoadNamedComponents([]ComponentConfig{
+		{Name: "lazydb", Type: "db", Lazy: true},
+		{Name: "tdb", Type: "db", Scope: ScopeTransient},
+	}))
+
+	// 按类型解析其他参数时不会创建懒加载与瞬态组件
+	assert.NoError(t, Provide(container, Provider{Name: "log", Constructor: func() injectLogger { return &injectLoggerImpl{prefix: "app"} }}))
+	assert.NoError(t, Invoke(container, func(log injectLogger) {}))
+	assert.Equal(t, 0, created)
+
+	// 尚未创建的组件与同批次的构造函数一样按声明的实例类型匹配
+	err := Invoke(container, func(db *injectDB) {})
+	assert.ErrorIs(t, err, ErrComponentAmbiguous)
+	assert.ErrorContains(t, err, "lazydb")
+	assert.ErrorContains(t, err, "tdb")
+	err = Provide(container, Provider{Name: "repo", Constructor: func(db *injectDB, log injectLogger) *injectRepo { return &injectRepo{db: db, log: log} }, Params: []ComponentName{"lazydb"}})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, created)
+}
+
+func TestProvideTransientDeps(t *testing.T) {
+	recorder := &reconcileRecorder{}
+	registry := NewFactoryRegistry()
+	MustRegister(registry, newReconcileFactory(recorder))
+	container := NewComponentContainer(WithFactoryRegistry(registry))
+	assert.NoError(t, container.LoadNamedComponents([]ComponentConfig{
+		{Name: "handler", Type: "reconcile", Scope: ScopeTransient, Config: reconcileConfig{Value: "t"}},
+	}))
+
+	// 注入构造函数的瞬态组件实例随构造出的组件一同销毁
+	assert.NoError(t, Provide(container, Provider{Name: "svc", Constructor: func(h string) int { return len(h) }}))
+	assert.Equal(t, []string{"handler:t"}, recorder.created)
+	assert.Empty(t, recorder.destroyed)
+	assert.NoError(t, container.UnloadNamedComponents([]ComponentName{"svc"}, false))
+	assert.Equal(t, []string{"handler:t"}, recorder.destroyed)
+
+	// 构造失败时立即销毁
+	err := Provide(container, Provider{Name: "bad", Constructor: func(h string) (int, error) { return 0, errors.New("boom") }})
+	assert.EqualError(t, err, "boom")
+	assert.Equal(t, []string{"handler:t", "handler:t"}, recorder.destroyed)
+
+	// 构造函数组件的工厂不会注册到共享的注册器中
+	_, err = registry.GetFactory(ProviderComponentType)
+	assert.ErrorIs(t, err, ErrComponentTypeNotRegistered)
+}
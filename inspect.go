@@ -0,0 +1,168 @@
+package compcont
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// 组件的运行状态
+type ComponentState string
+
+const (
+	ComponentStateReady    ComponentState = "ready"    // 实例已创建
+	ComponentStatePending  ComponentState = "pending"  // 懒加载组件尚未创建
+	ComponentStateCreating ComponentState = "creating" // 懒加载组件正在创建
+	ComponentStateFailed   ComponentState = "failed"   // 懒加载组件创建失败
+	ComponentStateDeferred ComponentState = "deferred" // 非单例组件，实例按需创建
+)
+
+// 具名组件的状态信息
+type ComponentInfo struct {
+	Name      ComponentName
+	Config    ComponentConfig // 组件的声明配置
+	State     ComponentState
+	Component Component // 组件，实例未创建时Instance为nil
+	Err       error     // 创建失败的原因
+}
+
+// 容器树中一个组件节点的信息
+type WalkNode struct {
+	ComponentInfo
+	Path  string // 组件的绝对路径
+	Owner string // 匿名组件所属组件的绝对路径，具名组件为空
+}
+
+// Walk 按构建顺序深度优先遍历容器及其子树中的所有组件，包括子容器中的组件与组件构造时加载的匿名组件，
+// 匿名组件在路径中以所属组件路径下的#序号表示，如/svc/#0。fn返回错误时停止遍历
+func Walk(container IComponentContainer, fn func(node WalkNode) error) error {
+	return walk(container, containerPath(container), fn)
+}
+
+// Query 在容器及其子树中查找绝对路径匹配pattern的组件，pattern中的每一段按path.Match匹配，
+// **匹配任意多段，如/infra/*/db、/**/db
+func Query(container IComponentContainer, pattern string) (nodes []WalkNode, err error) {
+	if !strings.HasPrefix(pattern, "/") {
+		err = fmt.Errorf("%w, query pattern %s must be an absolute path", ErrComponentNameInvalid, pattern)
+		return
+	}
+	patternParts := strings.Split(pattern[1:], "/")
+	for _, part := range patternParts {
+		if _, err = path.Match(part, ""); err != nil {
+			err = fmt.Errorf("%w, query pattern %s: %w", ErrComponentNameInvalid, pattern, err)
+			return
+		}
+	}
+	err = Walk(container, func(node WalkNode) error {
+		if matchPath(patternParts, strings.Split(node.Path[1:], "/")) {
+			nodes = append(nodes, node)
+		}
+		return nil
+	})
+	return
+}
+
+// Resolve 按路径定位组件，与组件配置中Refer的解析规则一致：以/开头的为从根容器开始的绝对路径，
+// 否则为相对container的路径，支持.与..
+func Resolve(container IComponentContainer, refer string) (component Component, err error) {
+	findPath, absolute, err := parseReferPath(refer)
+	if err != nil {
+		return
+	}
+	// 寻找到要引用的树节点，再从对应节点上获取组件
+	ctx, err := find(container, findPath, absolute)
+	if err != nil {
+		return
+	}
+	return ctx.Container.GetComponent(ctx.Config.Name)
+}
+
+// 按段匹配路径
+func matchPath(pattern, parts []string) bool {
+	if len(pattern) == 0 {
+		return len(parts) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(parts); i++ {
+			if matchPath(pattern[1:], parts[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(parts) == 0 {
+		return false
+	}
+	if ok, _ := path.Match(pattern[0], parts[0]); !ok {
+		return false
+	}
+	return matchPath(pattern[1:], parts[1:])
+}
+
+// InspectComponent 获取容器中一个具名组件的状态信息。容器未实现IComponentInspector时
+// 只能通过GetComponent获取组件，懒加载组件会因此被创建
+func InspectComponent(container IComponentContainer, name ComponentName) (info ComponentInfo, err error) {
+	if inspector, ok := container.(IComponentInspector); ok {
+		return inspector.InspectComponent(name)
+	}
+	component, err := container.GetComponent(name)
+	if err != nil {
+		return
+	}
+	return ComponentInfo{Name: name, Config: component.BuildContext.Config, State: ComponentStateReady, Component: component}, nil
+}
+
+// 容器在容器树中的绝对路径，根容器为空
+func containerPath(container IComponentContainer) string {
+	if container.GetParent() == nil {
+		return ""
+	}
+	return formatPath(container.GetContext().GetAbsolutePath())
+}
+
+func walk(container IComponentContainer, basePath string, fn func(node WalkNode) error) error {
+	for _, name := range container.LoadedComponentNames() {
+		info, err := InspectComponent(container, name)
+		if err != nil {
+			return err
+		}
+		node := WalkNode{ComponentInfo: info, Path: basePath + "/" + name.String()}
+		if err = walkComponent(container, node, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func walkComponent(container IComponentContainer, node WalkNode, fn func(node WalkNode) error) error {
+	if err := fn(node); err != nil {
+		return err
+	}
+	// 引用自其他位置的组件不重复遍历
+	component := node.Component
+	if component.BuildContext.Container != container {
+		return nil
+	}
+	if child, ok := component.Instance.(IComponentContainer); ok && child.GetParent() == container {
+		if err := walk(child, node.Path, fn); err != nil {
+			return err
+		}
+	}
+	for i, owned := range component.BuildContext.OwnedComponents() {
+		child := WalkNode{
+			ComponentInfo: ComponentInfo{Name: owned.BuildContext.Config.Name, Config: owned.BuildContext.Config, State: ComponentStateReady, Component: owned},
+			Path:          node.Path + "/" + anonymousSegment(i),
+			Owner:         node.Path,
+		}
+		if err := walkComponent(container, child, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// 匿名组件在路径中的名称，按加载顺序编号
+func anonymousSegment(i int) string {
+	return "#" + strconv.Itoa(i)
+}
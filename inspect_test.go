@@ -0,0 +1,57 @@
+package compcont
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWalkQueryResolve(t *testing.T) {
+	registry := NewFactoryRegistry()
+	MustRegister(registry, factoryA)
+	MustRegister(registry, factoryOwningB)
+	MustRegister(registry, newContainerFactory(registry))
+	root := NewComponentContainer(WithFactoryRegistry(registry))
+	err := root.LoadNamedComponents([]ComponentConfig{
+		{Name: "infra", Type: "container", Config: containerConfig{Components: []ComponentConfig{
+			{Name: "east", Type: "container", Config: containerConfig{Components: []ComponentConfig{{Name: "db", Type: "a"}}}},
+			{Name: "west", Type: "container", Config: containerConfig{Components: []ComponentConfig{{Name: "db", Type: "a"}}}},
+		}}},
+		{Name: "svc", Type: "b", Config: ConfigB{InnerA: TypedComponentConfig[ConfigA, IComponentA]{Type: "a"}}},
+	})
+	assert.NoError(t, err)
+
+	var paths []string
+	assert.NoError(t, Walk(root, func(node WalkNode) error {
+		paths = append(paths, node.Path)
+		if node.Path == "/svc/#0" {
+			assert.Equal(t, "/svc", node.Owner)
+		}
+		return nil
+	}))
+	assert.ElementsMatch(t, []string{"/infra", "/infra/east", "/infra/east/db", "/infra/west", "/infra/west/db", "/svc", "/svc/#0"}, paths)
+
+	nodes, err := Query(root, "/infra/*/db")
+	assert.NoError(t, err)
+	assert.Len(t, nodes, 2)
+	nodes, err = Query(root, "/**/db")
+	assert.NoError(t, err)
+	assert.Len(t, nodes, 2)
+	_, err = Query(root, "infra")
+	assert.ErrorIs(t, err, ErrComponentNameInvalid)
+
+	east, err := Resolve(root, "/infra/east")
+	assert.NoError(t, err)
+	eastContainer := east.Instance.(IComponentContainer)
+	db, err := Resolve(eastContainer, "../west/db")
+	assert.NoError(t, err)
+	assert.Equal(t, []ComponentName{"infra", "west", "db"}, db.BuildContext.GetAbsolutePath())
+
+	// 从子容器开始遍历时路径仍为绝对路径
+	paths = nil
+	assert.NoError(t, Walk(eastContainer, func(node WalkNode) error {
+		paths = append(paths, node.Path)
+		return nil
+	}))
+	assert.Equal(t, []string{"/infra/east/db"}, paths)
+}
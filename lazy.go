@@ -0,0 +1,104 @@
+package compcont
+
+import (
+	"fmt"
+	"sync"
+)
+
+// 懒加载组件的状态，并发的首次获取只会创建一次实例，等待中的调用方共享本次创建的结果。
+// 只缓存创建成功的实例，创建失败后的下一次获取会重新创建
+type lazyComponent struct {
+	mu        sync.Mutex
+	done      bool
+	component Component
+	inflight  *lazyCall // 正在进行的创建
+	lastErr   error     // 最近一次创建失败的原因，仅用于状态展示
+}
+
+// 一次懒加载组件的创建
+type lazyCall struct {
+	done      chan struct{}
+	component Component
+	err       error
+}
+
+// 返回已创建的组件实例，未创建或创建失败时返回false
+func (l *lazyComponent) created() (component Component, ok bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.component, l.done
+}
+
+// 与created相同，但存在正在进行的创建时会等待其结束，用于销毁组件时不遗漏刚创建的实例
+func (l *lazyComponent) awaitCreated() (component Component, ok bool) {
+	l.mu.Lock()
+	call := l.inflight
+	l.mu.Unlock()
+	if call != nil {
+		<-call.done
+	}
+	return l.created()
+}
+
+// 获取懒加载组件的状态，不会触发创建
+func (l *lazyComponent) state(placeholder Component) (state ComponentState, component Component, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	switch {
+	case l.done:
+		return ComponentStateReady, l.component, nil
+	case l.inflight != nil:
+		return ComponentStateCreating, placeholder, nil
+	case l.lastErr != nil:
+		return ComponentStateFailed, placeholder, l.lastErr
+	}
+	return ComponentStatePending, placeholder, nil
+}
+
+// 获取懒加载组件，首次获取时先加载其单例依赖，再创建实例。创建期间不持有锁，
+// 并发的获取等待同一次创建的结果
+func (c *ComponentContainer) getLazyComponent(placeholder Component) (component Component, err error) {
+	l := placeholder.lazy
+	l.mu.Lock()
+	if l.done {
+		defer l.mu.Unlock()
+		return l.component, nil
+	}
+	if call := l.inflight; call != nil {
+		l.mu.Unlock()
+		<-call.done
+		return call.component, call.err
+	}
+	call := &lazyCall{done: make(chan struct{})}
+	l.inflight = call
+	l.mu.Unlock()
+
+	call.component, call.err = c.createLazyComponent(placeholder.BuildContext.Config)
+	l.mu.Lock()
+	l.inflight = nil
+	if call.err == nil {
+		l.done, l.component, l.lastErr = true, call.component, nil
+	} else {
+		l.lastErr = call.err
+	}
+	l.mu.Unlock()
+	close(call.done)
+	return call.component, call.err
+}
+
+func (c *ComponentContainer) createLazyComponent(config ComponentConfig) (component Component, err error) {
+	for _, dep := range config.Deps {
+		name, _ := parseDep(dep)
+		if cfg, _ := c.declaredConfig(name); !c.hasComponent(name) || !cfg.Scope.isSingleton() {
+			continue
+		}
+		if _, err = c.GetComponent(name); err != nil {
+			err = fmt.Errorf("lazy init component %s, load dependency %s: %w", config.Name, name, err)
+			return
+		}
+	}
+	if component, err = c.MustLoadComponent(config); err != nil {
+		err = fmt.Errorf("lazy init component %s: %w", config.Name, err)
+	}
+	return
+}
@@ -0,0 +1,106 @@
+package compcont
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLazyComponent(t *testing.T) {
+	var created atomic.Int32
+	registry := NewFactoryRegistry()
+	MustRegister(registry, &TypedSimpleComponentFactory[reconcileConfig, string]{
+		TypeID: "lazy",
+		CreateInstanceFunc: func(ctx BuildContext, config reconcileConfig) (instance string, err error) {
+			created.Add(1)
+			if config.Fail {
+				err = errors.New("model not found")
+				return
+			}
+			return ctx.Config.Name.String(), nil
+		},
+	})
+	container := NewComponentContainer(WithFactoryRegistry(registry))
+
+	err := container.LoadNamedComponents([]ComponentConfig{
+		{Name: "store", Type: "lazy", Lazy: true},
+		{Name: "model", Type: "lazy", Lazy: true, Deps: []ComponentName{"store"}},
+		{Name: "broken", Type: "lazy", Lazy: true, Config: reconcileConfig{Fail: true}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, int32(0), created.Load())
+
+	// 并发的首次获取只创建一次，依赖同时被创建
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			model, err := GetComponent[string](container, "model")
+			assert.NoError(t, err)
+			assert.Equal(t, "model", model.Instance)
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, int32(2), created.Load())
+
+	// 创建失败不会被缓存，下一次获取重新创建
+	_, err = container.GetComponent("broken")
+	assert.ErrorContains(t, err, "model not found")
+	info, err := InspectComponent(container, "broken")
+	assert.NoError(t, err)
+	assert.Equal(t, ComponentStateFailed, info.State)
+	assert.ErrorContains(t, info.Err, "model not found")
+	_, err = container.GetComponent("broken")
+	assert.ErrorContains(t, err, "model not found")
+	assert.Equal(t, int32(4), created.Load())
+
+	// 懒加载组件仍需满足依赖关系
+	err = container.LoadNamedComponents([]ComponentConfig{
+		{Name: "orphan", Type: "lazy", Lazy: true, Deps: []ComponentName{"missing"}},
+	})
+	assert.ErrorIs(t, err, ErrComponentDependencyNotFound)
+}
+
+func TestLazyComponentRetry(t *testing.T) {
+	var attempts atomic.Int32
+	release := make(chan struct{})
+	registry := NewFactoryRegistry()
+	MustRegister(registry, &TypedSimpleComponentFactory[struct{}, string]{
+		TypeID: "flaky",
+		CreateInstanceFunc: func(ctx BuildContext, config struct{}) (instance string, err error) {
+			if attempts.Add(1) == 1 {
+				<-release
+				return "", errors.New("backend unavailable")
+			}
+			return "ok", nil
+		},
+	})
+	container := NewComponentContainer(WithFactoryRegistry(registry))
+	assert.NoError(t, container.LoadNamedComponents([]ComponentConfig{{Name: "client", Type: "flaky", Lazy: true}}))
+
+	// 创建期间不持有锁，查询状态不会阻塞
+	failed := make(chan error)
+	go func() {
+		_, err := container.GetComponent("client")
+		failed <- err
+	}()
+	assert.Eventually(t, func() bool {
+		info, err := InspectComponent(container, "client")
+		return err == nil && info.State == ComponentStateCreating
+	}, time.Second, time.Millisecond)
+	close(release)
+	assert.ErrorContains(t, <-failed, "backend unavailable")
+
+	// 失败后重试成功，之后使用缓存的实例
+	client, err := GetComponent[string](container, "client")
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", client.Instance)
+	_, err = container.GetComponent("client")
+	assert.NoError(t, err)
+	assert.Equal(t, int32(2), attempts.Load())
+}
@@ -0,0 +1,153 @@
+package compcont
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// 需要在容器启动时执行启动逻辑的组件实例可实现该接口，ComponentContainer自身实现了该接口，子容器随父容器一同启动
+type IStartable interface {
+	Start(ctx context.Context) error
+}
+
+// 需要在容器停止时执行停止逻辑的组件实例可实现该接口
+type IStoppable interface {
+	Stop(ctx context.Context) error
+}
+
+// Start 按构建顺序启动容器中归属于当前容器的单例组件，已启动的组件不会重复启动，
+// 尚未创建的懒加载组件不会被启动。任意组件启动失败时，已启动的组件会按逆序停止。
+// 容器启动后，热更新中新建的组件会被自动启动，被替换与被卸载的组件会在销毁前被停止
+func (c *ComponentContainer) Start(ctx context.Context) (err error) {
+	c.dispatcher.hold() // 持有锁期间的事件在解锁后通知
+	defer c.dispatcher.release()
+	c.lifecycleMu.Lock()
+	defer c.lifecycleMu.Unlock()
+
+	if err = c.start(ctx); err != nil {
+		if stopErr := c.stop(ctx); stopErr != nil {
+			err = errors.Join(err, fmt.Errorf("rollback failed: %w", stopErr))
+		}
+		return
+	}
+	c.running.Store(true)
+	c.supervisor.start()
+	return
+}
+
+// 启动尚未启动的组件，遇到启动失败的组件时停止并返回错误
+func (c *ComponentContainer) start(ctx context.Context) (err error) {
+	for _, name := range c.LoadedComponentNames() {
+		if slices.Contains(c.started, name) {
+			continue
+		}
+		component, ok := c.lifecycleComponent(name)
+		if !ok {
+			continue
+		}
+		startable, ok := component.Instance.(IStartable)
+		if !ok {
+			continue
+		}
+		spanCtx, span := c.tracer.StartSpan(ctx, SpanStart, componentSpanOptions(component.BuildContext))
+		start := time.Now()
+		startErr := startable.Start(spanCtx)
+		span.End(startErr)
+		component.BuildContext.recordPhase(PhaseStart, start, time.Since(start))
+		event := Event{Type: EventStarted, Time: time.Now(), Context: component.BuildContext, Duration: time.Since(start), Err: startErr}
+		if startErr != nil {
+			event.Type = EventStartFailed
+		}
+		c.emit(&event)
+		if startErr != nil {
+			return fmt.Errorf("start component %s: %w", name, startErr)
+		}
+		c.started = append(c.started, name)
+	}
+	return
+}
+
+// Stop 按启动的逆序停止已启动的组件，单个组件停止失败不影响其余组件
+func (c *ComponentContainer) Stop(ctx context.Context) error {
+	c.dispatcher.hold() // 持有锁期间的事件在解锁后通知
+	defer c.dispatcher.release()
+	c.lifecycleMu.Lock()
+	defer c.lifecycleMu.Unlock()
+	c.running.Store(false)
+	c.supervisor.stop() // 取消等待中的重建
+	return c.stop(ctx)
+}
+
+func (c *ComponentContainer) stop(ctx context.Context) error {
+	var errs []error
+	for _, name := range slices.Backward(c.started) {
+		if component, ok := c.lifecycleComponent(name); ok {
+			if err := c.stopComponent(ctx, name, component); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	c.started = nil
+	return errors.Join(errs...)
+}
+
+// 停止一个组件实例
+func (c *ComponentContainer) stopComponent(ctx context.Context, name ComponentName, component Component) (err error) {
+	stoppable, ok := component.Instance.(IStoppable)
+	if !ok {
+		return
+	}
+	spanCtx, span := c.tracer.StartSpan(ctx, SpanStop, componentSpanOptions(component.BuildContext))
+	start := time.Now()
+	stopErr := stoppable.Stop(spanCtx)
+	span.End(stopErr)
+	event := Event{Type: EventStopped, Time: time.Now(), Context: component.BuildContext, Duration: time.Since(start), Err: stopErr}
+	if stopErr != nil {
+		event.Type = EventStopFailed
+		err = fmt.Errorf("stop component %s: %w", name, stopErr)
+	}
+	c.emit(&event)
+	return
+}
+
+// 停止即将被销毁的已启动组件，components为组件名到即将销毁的实例
+func (c *ComponentContainer) stopForTeardown(ctx context.Context, names []ComponentName, components map[ComponentName]Component) error {
+	var errs []error
+	for _, name := range names {
+		i := slices.Index(c.started, name)
+		if i < 0 {
+			continue
+		}
+		c.started = slices.Delete(c.started, i, i+1)
+		component := components[name]
+		if component.lazy != nil {
+			if component, _ = component.lazy.awaitCreated(); component.Instance == nil {
+				continue
+			}
+		}
+		if err := c.stopComponent(ctx, name, component); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// 获取参与启动与停止的组件：归属于当前容器且已创建的单例组件
+func (c *ComponentContainer) lifecycleComponent(name ComponentName) (component Component, ok bool) {
+	c.mu.RLock()
+	component, ok = c.components[name]
+	c.mu.RUnlock()
+	if !ok || !component.BuildContext.Config.Scope.isSingleton() {
+		return component, false
+	}
+	if component.lazy != nil {
+		if component, ok = component.lazy.created(); !ok {
+			return
+		}
+	}
+	ok = component.BuildContext.Container == IComponentContainer(c) && component.BuildContext.Mount != nil
+	return
+}
@@ -0,0 +1,176 @@
+package compcont
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type lifecycleConfig struct {
+	Value string
+	Fail  bool
+}
+
+type lifecycleService struct {
+	name string
+	fail bool
+	log  *[]string
+}
+
+func (s *lifecycleService) Start(ctx context.Context) error {
+	if s.fail {
+		return errors.New("port in use")
+	}
+	*s.log = append(*s.log, "start "+s.name)
+	return nil
+}
+
+func (s *lifecycleService) Stop(ctx context.Context) error {
+	*s.log = append(*s.log, "stop "+s.name)
+	return nil
+}
+
+func newLifecycleFactory(log *[]string) IComponentFactory {
+	return &TypedSimpleComponentFactory[lifecycleConfig, *lifecycleService]{
+		TypeID: "svc",
+		CreateInstanceFunc: func(ctx BuildContext, config lifecycleConfig) (instance *lifecycleService, err error) {
+			return &lifecycleService{name: ctx.Config.Name.String() + config.Value, fail: config.Fail, log: log}, nil
+		},
+	}
+}
+
+func TestEventHooks(t *testing.T) {
+	var log []string
+	registry := NewFactoryRegistry()
+	MustRegister(registry, newLifecycleFactory(&log))
+	MustRegister(registry, newContainerFactory(registry))
+	container := NewComponentContainer(WithFactoryRegistry(registry)).(*ComponentContainer)
+
+	// 子容器中的事件冒泡到根容器
+	var types []EventType
+	var paths []string
+	unsubscribe := container.Subscribe(func(e *Event) error {
+		types = append(types, e.Type)
+		paths = append(paths, e.Path())
+		return nil
+	})
+	// 否决名为blocked的组件，并为其余组件调整配置
+	container.Subscribe(func(e *Event) error {
+		if e.Type != EventBeforeCreate || e.Context.Config.Type != "svc" {
+			return nil
+		}
+		if e.Context.Config.Name == "blocked" {
+			return errors.New("not allowed")
+		}
+		e.Context.Config.Config = map[string]any{"Value": "!"}
+		return nil
+	})
+
+	err := container.LoadNamedComponents([]ComponentConfig{
+		{Name: "infra", Type: "container", Config: containerConfig{Components: []ComponentConfig{
+			{Name: "db", Type: "svc"},
+		}}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []EventType{EventBeforeCreate, EventBeforeCreate, EventAfterCreate, EventAfterCreate}, types)
+	assert.Equal(t, []string{"/infra", "/infra/db", "/infra/db", "/infra"}, paths)
+	db, err := Resolve(container, "/infra/db")
+	assert.NoError(t, err)
+	assert.Equal(t, "db!", db.Instance.(*lifecycleService).name)
+
+	err = container.LoadNamedComponents([]ComponentConfig{{Name: "blocked", Type: "svc"}})
+	assert.ErrorIs(t, err, ErrComponentVetoed)
+	assert.Equal(t, EventCreateFailed, types[len(types)-1])
+	assert.NotContains(t, container.LoadedComponentNames(), ComponentName("blocked"))
+
+	// 取消订阅后不再收到事件
+	unsubscribe()
+	count := len(types)
+	assert.NoError(t, container.UnloadNamedComponents([]ComponentName{"infra"}, false))
+	assert.Len(t, types, count)
+	events := container.RecentEvents()
+	assert.Equal(t, EventAfterDestroy, events[len(events)-1].Type)
+}
+
+func TestStartStop(t *testing.T) {
+	var log []string
+	registry := NewFactoryRegistry()
+	MustRegister(registry, newLifecycleFactory(&log))
+	MustRegister(registry, newContainerFactory(registry))
+	container := NewComponentContainer(WithFactoryRegistry(registry)).(*ComponentContainer)
+	err := container.LoadNamedComponents([]ComponentConfig{
+		{Name: "infra", Type: "container", Config: containerConfig{Components: []ComponentConfig{
+			{Name: "db", Type: "svc"},
+		}}},
+		{Name: "api", Type: "svc", Deps: []ComponentName{"infra"}},
+		{Name: "worker", Type: "svc", Lazy: true},
+	})
+	assert.NoError(t, err)
+
+	var started []string
+	container.Subscribe(func(e *Event) error {
+		if e.Type == EventStarted {
+			started = append(started, e.Path())
+		}
+		return nil
+	})
+
+	// 子容器随父容器启动，未创建的懒加载组件不启动
+	ctx := context.Background()
+	assert.NoError(t, container.Start(ctx))
+	assert.NoError(t, container.Start(ctx))
+	assert.Equal(t, []string{"start db", "start api"}, log)
+	assert.Equal(t, []string{"/infra/db", "/infra", "/api"}, started)
+
+	// 容器启动后，重建的组件先停止旧实例再启动新实例
+	assert.NoError(t, container.RestartComponents("api"))
+	assert.Equal(t, []string{"start db", "start api", "stop api", "start api"}, log)
+
+	log = nil
+	assert.NoError(t, container.Stop(ctx))
+	assert.Equal(t, []string{"stop api", "stop db"}, log)
+
+	// 启动失败时已启动的组件按逆序停止
+	log = nil
+	err = container.LoadNamedComponents([]ComponentConfig{
+		{Name: "broken", Type: "svc", Deps: []ComponentName{"api"}, Config: lifecycleConfig{Fail: true}},
+	})
+	assert.NoError(t, err)
+	err = container.Start(ctx)
+	assert.ErrorContains(t, err, "port in use")
+	assert.Equal(t, []string{"start db", "start api", "stop api", "stop db"}, log)
+}
+
+func TestReentrantEventHooks(t *testing.T) {
+	var log []string
+	registry := NewFactoryRegistry()
+	MustRegister(registry, newLifecycleFactory(&log))
+	container := NewComponentContainer(WithFactoryRegistry(registry)).(*ComponentContainer)
+	assert.NoError(t, container.LoadNamedComponents([]ComponentConfig{{Name: "api", Type: "svc"}}))
+
+	// 订阅者在启动事件中重建组件，在重建后的启动事件中停止容器，均不会死锁
+	ctx := context.Background()
+	restarted := false
+	container.Subscribe(func(e *Event) error {
+		if e.Type != EventStarted {
+			return nil
+		}
+		if !restarted {
+			restarted = true
+			return container.RestartComponents("api")
+		}
+		return container.Stop(ctx)
+	})
+	done := make(chan error, 1)
+	go func() { done <- container.Start(ctx) }()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("event hook deadlocked")
+	}
+	assert.Equal(t, []string{"start api", "stop api", "start api", "stop api"}, log)
+}
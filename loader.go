@@ -0,0 +1,106 @@
+package compcont
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// 支持的配置文件扩展名
+var configFileExts = []string{".json", ".yaml", ".yml"}
+
+// 解析一份组件配置，内容为组件配置列表，format为json或yaml
+func ParseConfigs(data []byte, format string) (configs []ComponentConfig, err error) {
+	switch strings.TrimPrefix(strings.ToLower(format), ".") {
+	case "json":
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(&configs)
+	case "yaml", "yml":
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		err = decoder.Decode(&configs)
+		if errors.Is(err, io.EOF) { // 空文件
+			err = nil
+		}
+	default:
+		err = fmt.Errorf("unsupported config format %q", format)
+	}
+	if err != nil {
+		err = fmt.Errorf("%w, %w", ErrComponentConfigInvalid, err)
+	}
+	return
+}
+
+// 从文件中加载组件配置，根据扩展名选择JSON或YAML格式
+func LoadConfigFile(path string) (configs []ComponentConfig, err error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+	configs, err = ParseConfigs(data, filepath.Ext(path))
+	if err != nil {
+		err = fmt.Errorf("load config file %s: %w", path, err)
+	}
+	return
+}
+
+// 从多个文件或目录中加载组件配置，目录下的配置文件按文件名顺序加载，忽略以.开头的文件
+func LoadConfigPaths(paths ...string) (configs []ComponentConfig, err error) {
+	files, err := resolveConfigFiles(paths)
+	if err != nil {
+		return
+	}
+	for _, file := range files {
+		var fileConfigs []ComponentConfig
+		fileConfigs, err = LoadConfigFile(file)
+		if err != nil {
+			return
+		}
+		configs = append(configs, fileConfigs...)
+	}
+	return
+}
+
+// 将文件与目录展开为配置文件列表
+func resolveConfigFiles(paths []string) (files []string, err error) {
+	for _, path := range paths {
+		var info os.FileInfo
+		info, err = os.Stat(path)
+		if err != nil {
+			return
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+		var entries []os.DirEntry
+		entries, err = os.ReadDir(path)
+		if err != nil {
+			return
+		}
+		for _, entry := range entries {
+			// ConfigMap挂载目录中的..data等隐藏文件不参与加载
+			if strings.HasPrefix(entry.Name(), ".") || !slices.Contains(configFileExts, strings.ToLower(filepath.Ext(entry.Name()))) {
+				continue
+			}
+			file := filepath.Join(path, entry.Name())
+			// 使用Stat以跟随符号链接
+			if info, err = os.Stat(file); err != nil {
+				return
+			}
+			if !info.IsDir() {
+				files = append(files, file)
+			}
+		}
+	}
+	return
+}
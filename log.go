@@ -0,0 +1,44 @@
+package compcont
+
+import (
+	"context"
+	"log/slog"
+)
+
+// 丢弃所有日志的Handler，未设置Logger时使用
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (h discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return h }
+func (h discardHandler) WithGroup(string) slog.Handler           { return h }
+
+var discardLogger = slog.New(discardHandler{})
+
+// 组件专属的日志记录器，预置了组件的绝对路径与类型
+func (c BuildContext) Logger() *slog.Logger {
+	if c.logger == nil {
+		return discardLogger
+	}
+	return c.logger
+}
+
+// 容器自身的日志记录器，预置了容器的绝对路径
+func (c *ComponentContainer) log() *slog.Logger {
+	path := containerPath(c)
+	if path == "" {
+		path = "/"
+	}
+	return c.logger.With("container", path)
+}
+
+// 以组件的日志记录器记录生命周期事件，失败的事件以Error级别记录
+func logEvent(e *Event) {
+	logger := e.Context.Logger()
+	msg := "component " + string(e.Type)
+	if e.Err != nil {
+		logger.Error(msg, "duration", e.Duration, "error", e.Err)
+		return
+	}
+	logger.Debug(msg, "duration", e.Duration)
+}
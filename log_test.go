@@ -0,0 +1,55 @@
+package compcont
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	registry := NewFactoryRegistry()
+	MustRegister(registry, &TypedSimpleComponentFactory[struct{}, string]{
+		TypeID: "greeter",
+		CreateInstanceFunc: func(ctx BuildContext, config struct{}) (instance string, err error) {
+			ctx.Logger().Info("hello")
+			return
+		},
+	})
+	MustRegister(registry, newContainerFactory(registry))
+	container := NewComponentContainer(WithFactoryRegistry(registry), WithLogger(logger))
+	err := container.LoadNamedComponents([]ComponentConfig{
+		{Name: "infra", Type: "container", Config: containerConfig{Components: []ComponentConfig{
+			{Name: "greeter", Type: "greeter"},
+		}}},
+		{Name: "off", Type: "greeter", When: "false"},
+	})
+	assert.NoError(t, err)
+
+	var records []map[string]any
+	for _, line := range bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n")) {
+		var record map[string]any
+		assert.NoError(t, json.Unmarshal(line, &record))
+		records = append(records, record)
+	}
+	find := func(msg string, path string) map[string]any {
+		for _, record := range records {
+			if record["msg"] == msg && (record["path"] == path || record["container"] == path) {
+				return record
+			}
+		}
+		t.Fatalf("log %q of %s not found in %s", msg, path, buf.String())
+		return nil
+	}
+
+	assert.Equal(t, []any{"off"}, find("components skipped", "/")["names"])
+	assert.Equal(t, []any{"greeter"}, find("build order", "/infra")["names"])
+	// 子容器继承日志记录器，工厂获得预置了路径与类型的日志记录器
+	assert.Equal(t, "greeter", find("hello", "/infra/greeter")["type"])
+	assert.Contains(t, find("component after_create", "/infra/greeter"), "duration")
+	find("component after_create", "/infra")
+}
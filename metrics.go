@@ -0,0 +1,111 @@
+package compcont
+
+import (
+	"sync"
+)
+
+// 指标的接收方，容器在组件生命周期中调用，实现需要是并发安全的
+type IMetricsSink interface {
+	AddCounter(name string, labels map[string]string, delta float64)       // 累加计数器
+	SetGauge(name string, labels map[string]string, value float64)         // 设置仪表盘的当前值
+	ObserveHistogram(name string, labels map[string]string, value float64) // 记录直方图的一个观测值
+}
+
+// 容器上报的指标名称，匿名组件的path标签为所属组件的路径，未归属任何组件时为所在容器的路径
+const (
+	MetricComponentsLoaded      = "compcont_components_loaded_total"           // 创建成功的组件数，标签为type与path
+	MetricComponentsFailed      = "compcont_components_failed_total"           // 创建失败的组件数，标签为type与path
+	MetricCreateDurationSeconds = "compcont_component_create_duration_seconds" // 组件创建耗时的直方图，标签为type与path
+	MetricDestroyErrors         = "compcont_component_destroy_errors_total"    // 组件销毁失败数，标签为type与path
+	MetricReloads               = "compcont_reloads_total"                     // 热更新次数，标签为path（容器路径）与result（success/failure）
+	MetricRestarts              = "compcont_restarts_total"                    // 监督者重建组件的次数，标签为type、path与result（success/failure/gave_up）
+	MetricLiveComponents        = "compcont_live_components"                   // 当前存活的具名组件实例数，标签为type与path，不含匿名组件与瞬态组件
+)
+
+// 将生命周期事件转换为指标，同一棵容器树共享以统计存活的组件数
+type metricsRecorder struct {
+	sink IMetricsSink
+	mu   sync.Mutex
+	live map[liveKey]int
+}
+
+type liveKey struct {
+	typ  ComponentTypeID
+	path string
+}
+
+func (r *metricsRecorder) observe(e *Event) {
+	if r == nil {
+		return
+	}
+	path := e.Path()
+	if e.Context.Config.Name == "" {
+		path = e.Context.owner
+	}
+	labels := map[string]string{"type": e.Context.Config.Type.String(), "path": path}
+	// 匿名组件随所属组件存活，瞬态组件的实例由调用方管理，均不计入存活数量
+	live := e.Context.Config.Name != "" && e.Context.Config.Scope != ScopeTransient
+	switch e.Type {
+	case EventAfterCreate:
+		r.sink.AddCounter(MetricComponentsLoaded, labels, 1)
+		r.sink.ObserveHistogram(MetricCreateDurationSeconds, labels, e.Duration.Seconds())
+		if live {
+			r.addLive(liveKey{e.Context.Config.Type, path}, 1)
+		}
+	case EventCreateFailed:
+		r.sink.AddCounter(MetricComponentsFailed, labels, 1)
+	case EventAfterDestroy:
+		if live {
+			r.addLive(liveKey{e.Context.Config.Type, path}, -1)
+		}
+	case EventDestroyFailed:
+		r.sink.AddCounter(MetricDestroyErrors, labels, 1)
+		if live {
+			r.addLive(liveKey{e.Context.Config.Type, path}, -1)
+		}
+	case EventRestarted:
+		r.restarted(labels, "success")
+	case EventRestartFailed:
+		r.restarted(labels, "failure")
+	case EventRestartGaveUp:
+		r.restarted(labels, "gave_up")
+	}
+}
+
+func (r *metricsRecorder) restarted(labels map[string]string, result string) {
+	labels["result"] = result
+	r.sink.AddCounter(MetricRestarts, labels, 1)
+}
+
+// 热更新期间新旧实例短暂共存，因此按数量而非是否存在统计
+func (r *metricsRecorder) addLive(key liveKey, delta int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.live == nil {
+		r.live = make(map[liveKey]int)
+	}
+	r.live[key] += delta
+	r.sink.SetGauge(MetricLiveComponents, map[string]string{"type": key.typ.String(), "path": key.path}, float64(r.live[key]))
+}
+
+// 记录一次热更新的结果
+func (r *metricsRecorder) reloaded(path string, err error) {
+	if r == nil {
+		return
+	}
+	if path == "" {
+		path = "/"
+	}
+	result := "success"
+	if err != nil {
+		result = "failure"
+	}
+	r.sink.AddCounter(MetricReloads, map[string]string{"path": path, "result": result}, 1)
+}
+
+// 设置容器的指标接收方，未设置时继承父容器的设置
+func WithMetrics(sink IMetricsSink) Option {
+	return func(o *options) {
+		o.metrics = &metricsRecorder{sink: sink}
+	}
+}
@@ -0,0 +1,214 @@
+package compcont
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"maps"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// 直方图的默认桶上界，单位为秒
+var DefaultHistogramBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// 直方图的快照
+type HistogramSnapshot struct {
+	Buckets []float64 // 桶上界，升序
+	Counts  []uint64  // 每个桶的累计观测数，即不大于对应上界的观测数
+	Count   uint64
+	Sum     float64
+}
+
+type metricKind string
+
+const (
+	metricCounter   metricKind = "counter"
+	metricGauge     metricKind = "gauge"
+	metricHistogram metricKind = "histogram"
+)
+
+type metricSeries struct {
+	labels    map[string]string
+	value     float64
+	histogram *HistogramSnapshot
+}
+
+type metricFamily struct {
+	kind   metricKind
+	series map[string]*metricSeries // 以序列化的标签为key
+}
+
+// 内存中的指标实现，可以以Prometheus文本格式输出
+type MemoryMetrics struct {
+	buckets  []float64
+	mu       sync.Mutex
+	families map[string]*metricFamily
+}
+
+// NewMemoryMetrics 创建内存指标，buckets为直方图的桶上界，不填时使用DefaultHistogramBuckets
+func NewMemoryMetrics(buckets ...float64) *MemoryMetrics {
+	if len(buckets) == 0 {
+		buckets = DefaultHistogramBuckets
+	}
+	buckets = slices.Clone(buckets)
+	slices.Sort(buckets)
+	return &MemoryMetrics{buckets: buckets, families: make(map[string]*metricFamily)}
+}
+
+// 获取指定名称与标签的序列，不存在时创建
+func (m *MemoryMetrics) series(name string, kind metricKind, labels map[string]string) *metricSeries {
+	family, ok := m.families[name]
+	if !ok {
+		family = &metricFamily{kind: kind, series: make(map[string]*metricSeries)}
+		m.families[name] = family
+	}
+	key := formatLabels(labels)
+	s, ok := family.series[key]
+	if !ok {
+		s = &metricSeries{labels: maps.Clone(labels)}
+		if kind == metricHistogram {
+			s.histogram = &HistogramSnapshot{Buckets: m.buckets, Counts: make([]uint64, len(m.buckets))}
+		}
+		family.series[key] = s
+	}
+	return s
+}
+
+// AddCounter implements IMetricsSink.
+func (m *MemoryMetrics) AddCounter(name string, labels map[string]string, delta float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.series(name, metricCounter, labels).value += delta
+}
+
+// SetGauge implements IMetricsSink.
+func (m *MemoryMetrics) SetGauge(name string, labels map[string]string, value float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.series(name, metricGauge, labels).value = value
+}
+
+// ObserveHistogram implements IMetricsSink.
+func (m *MemoryMetrics) ObserveHistogram(name string, labels map[string]string, value float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	h := m.series(name, metricHistogram, labels).histogram
+	for i, bound := range h.Buckets {
+		if value <= bound {
+			h.Counts[i]++
+		}
+	}
+	h.Count++
+	h.Sum += value
+}
+
+// 获取计数器或仪表盘的当前值，不存在时返回0
+func (m *MemoryMetrics) Value(name string, labels map[string]string) float64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if family, ok := m.families[name]; ok {
+		if s, ok := family.series[formatLabels(labels)]; ok {
+			return s.value
+		}
+	}
+	return 0
+}
+
+// 获取直方图的快照，不存在时返回false
+func (m *MemoryMetrics) Histogram(name string, labels map[string]string) (snapshot HistogramSnapshot, ok bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	family, ok := m.families[name]
+	if !ok || family.kind != metricHistogram {
+		return snapshot, false
+	}
+	s, ok := family.series[formatLabels(labels)]
+	if !ok {
+		return
+	}
+	snapshot = *s.histogram
+	snapshot.Counts = slices.Clone(s.histogram.Counts)
+	return
+}
+
+// 以Prometheus文本格式输出所有指标，指标与序列按名称排序
+func (m *MemoryMetrics) WritePrometheus(w io.Writer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var b strings.Builder
+	for _, name := range slices.Sorted(maps.Keys(m.families)) {
+		family := m.families[name]
+		fmt.Fprintf(&b, "# TYPE %s %s\n", name, family.kind)
+		for _, key := range slices.Sorted(maps.Keys(family.series)) {
+			s := family.series[key]
+			if family.kind != metricHistogram {
+				fmt.Fprintf(&b, "%s%s %s\n", name, key, formatFloat(s.value))
+				continue
+			}
+			h := s.histogram
+			for i, bound := range h.Buckets {
+				fmt.Fprintf(&b, "%s_bucket%s %d\n", name, formatLabels(withLabel(s.labels, "le", formatFloat(bound))), h.Counts[i])
+			}
+			fmt.Fprintf(&b, "%s_bucket%s %d\n", name, formatLabels(withLabel(s.labels, "le", "+Inf")), h.Count)
+			fmt.Fprintf(&b, "%s_sum%s %s\n", name, key, formatFloat(h.Sum))
+			fmt.Fprintf(&b, "%s_count%s %d\n", name, key, h.Count)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func withLabel(labels map[string]string, name, value string) map[string]string {
+	labels = maps.Clone(labels)
+	if labels == nil {
+		labels = make(map[string]string)
+	}
+	labels[name] = value
+	return labels
+}
+
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// 按Prometheus文本格式序列化标签，标签按名称排序，le标签排在最后
+func formatLabels(labels map[string]string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	names := slices.SortedFunc(maps.Keys(labels), func(a, b string) int {
+		return cmp.Or(cmp.Compare(boolInt(a == "le"), boolInt(b == "le")), cmp.Compare(a, b))
+	})
+	var b strings.Builder
+	b.WriteString("{")
+	for i, name := range names {
+		if i > 0 {
+			b.WriteString(",")
+		}
+		b.WriteString(name)
+		b.WriteString(`="`)
+		b.WriteString(labelValueEscaper.Replace(labels[name]))
+		b.WriteString(`"`)
+	}
+	b.WriteString("}")
+	return b.String()
+}
+
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
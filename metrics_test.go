@@ -0,0 +1,113 @@
+package compcont
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetrics(t *testing.T) {
+	metrics := NewMemoryMetrics()
+	registry := NewFactoryRegistry()
+	MustRegister(registry, newReconcileFactory(&reconcileRecorder{}))
+	MustRegister(registry, &TypedSimpleComponentFactory[struct{}, string]{
+		TypeID: "leaky",
+		CreateInstanceFunc: func(ctx BuildContext, config struct{}) (instance string, err error) {
+			return "leaky", nil
+		},
+		DestroyInstanceFunc: func(ctx BuildContext, instance string) (err error) {
+			return errors.New("close failed")
+		},
+	})
+	container := NewComponentContainer(WithFactoryRegistry(registry), WithMetrics(metrics)).(*ComponentContainer)
+
+	err := container.LoadNamedComponents([]ComponentConfig{
+		{Name: "a", Type: "reconcile"},
+		{Name: "b", Type: "reconcile", Deps: []ComponentName{"a"}},
+		{Name: "leak", Type: "leaky"},
+	})
+	assert.NoError(t, err)
+	a := map[string]string{"type": "reconcile", "path": "/a"}
+	assert.Equal(t, 1.0, metrics.Value(MetricComponentsLoaded, a))
+	assert.Equal(t, 1.0, metrics.Value(MetricLiveComponents, a))
+	assert.Equal(t, 1.0, metrics.Value(MetricLiveComponents, map[string]string{"type": "reconcile", "path": "/b"}))
+	h, ok := metrics.Histogram(MetricCreateDurationSeconds, a)
+	assert.True(t, ok)
+	assert.Equal(t, uint64(1), h.Count)
+
+	// 热更新失败
+	plan, err := container.PlanReconcile([]ComponentConfig{
+		{Name: "a", Type: "reconcile", Config: reconcileConfig{Fail: true}},
+		{Name: "b", Type: "reconcile", Deps: []ComponentName{"a"}},
+		{Name: "leak", Type: "leaky"},
+	})
+	assert.NoError(t, err)
+	assert.Error(t, container.ApplyReconcile(plan))
+	assert.Equal(t, 1.0, metrics.Value(MetricComponentsFailed, a))
+	assert.Equal(t, 1.0, metrics.Value(MetricReloads, map[string]string{"path": "/", "result": "failure"}))
+
+	assert.Error(t, container.UnloadNamedComponents([]ComponentName{"leak"}, false))
+	assert.Equal(t, 1.0, metrics.Value(MetricDestroyErrors, map[string]string{"type": "leaky", "path": "/leak"}))
+	assert.Equal(t, 0.0, metrics.Value(MetricLiveComponents, map[string]string{"type": "leaky", "path": "/leak"}))
+
+	var buf bytes.Buffer
+	assert.NoError(t, metrics.WritePrometheus(&buf))
+	text := buf.String()
+	assert.Contains(t, text, "# TYPE compcont_components_loaded_total counter\n")
+	assert.Contains(t, text, `compcont_components_loaded_total{path="/a",type="reconcile"} 1`+"\n")
+	assert.Contains(t, text, `compcont_component_create_duration_seconds_bucket{path="/a",type="reconcile",le="+Inf"} 1`+"\n")
+	assert.Contains(t, text, `compcont_live_components{path="/a",type="reconcile"} 1`+"\n")
+}
+
+func TestRestartMetrics(t *testing.T) {
+	var mu sync.Mutex
+	metrics := NewMemoryMetrics()
+	registry := NewFactoryRegistry()
+	MustRegister(registry, newConsumerFactory(&mu, make(map[string]int)))
+	MustRegister(registry, newReconcileFactory(&reconcileRecorder{}))
+	MustRegister(registry, &TypedSimpleComponentFactory[struct{}, string]{
+		TypeID: "owner",
+		CreateInstanceFunc: func(ctx BuildContext, config struct{}) (instance string, err error) {
+			_, err = ctx.LoadAnonymousComponent(ComponentConfig{Type: "reconcile"})
+			return
+		},
+	})
+	container := NewComponentContainer(WithFactoryRegistry(registry), WithMetrics(metrics),
+		WithSupervisor(SupervisorOptions{Backoff: time.Millisecond})).(*ComponentContainer)
+	assert.NoError(t, container.LoadNamedComponents([]ComponentConfig{{Name: "a", Type: "consumer"}}))
+	assert.NoError(t, container.Start(context.Background()))
+
+	// 监督者的重建单独计数，不计入热更新次数
+	restarted := waitEvent(t, container, EventRestarted)
+	a, _ := GetComponent[*consumer](container, "a")
+	a.Instance.ctx.ReportFatal(errors.New("connection lost"))
+	restarted()
+	assert.Equal(t, 1.0, metrics.Value(MetricRestarts, map[string]string{"type": "consumer", "path": "/a", "result": "success"}))
+	assert.NoError(t, container.RestartComponents("a"))
+	assert.Equal(t, 0.0, metrics.Value(MetricReloads, map[string]string{"path": "/", "result": "success"}))
+
+	// 匿名组件以所属组件的路径为标签，不计入存活数量
+	assert.NoError(t, container.LoadNamedComponents([]ComponentConfig{{Name: "o", Type: "owner"}}))
+	anonymous := map[string]string{"type": "reconcile", "path": "/o"}
+	assert.Equal(t, 1.0, metrics.Value(MetricComponentsLoaded, anonymous))
+	assert.Equal(t, 0.0, metrics.Value(MetricLiveComponents, anonymous))
+	assert.Equal(t, 1.0, metrics.Value(MetricLiveComponents, map[string]string{"type": "owner", "path": "/o"}))
+	assert.NoError(t, container.UnloadNamedComponents([]ComponentName{"o"}, false))
+	assert.Equal(t, 0.0, metrics.Value(MetricLiveComponents, map[string]string{"type": "owner", "path": "/o"}))
+
+	// 未归属任何组件的匿名组件以所在容器的路径为标签
+	unowned, err := container.LoadAnonymousComponent(ComponentConfig{Type: "reconcile"})
+	assert.NoError(t, err)
+	assert.NoError(t, container.DestroyComponent(unowned))
+	assert.Equal(t, 1.0, metrics.Value(MetricComponentsLoaded, map[string]string{"type": "reconcile", "path": "/"}))
+	assert.Equal(t, 1.0, metrics.Value(MetricLiveComponents, map[string]string{"type": "consumer", "path": "/a"}))
+}
+
+func TestFormatLabels(t *testing.T) {
+	assert.Equal(t, `{b="x\"y\\z\n",le="0.5"}`, formatLabels(map[string]string{"le": "0.5", "b": "x\"y\\z\n"}))
+}
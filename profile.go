@@ -0,0 +1,284 @@
+package compcont
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// 组件加载与启动过程中的阶段
+type ProfilePhase string
+
+const (
+	PhaseDependencyWait ProfilePhase = "dependency_wait" // 并行加载时等待依赖组件就绪的耗时
+	PhaseDecode         ProfilePhase = "decode"          // 解码组件配置的耗时，包含在create阶段内
+	PhaseCreate         ProfilePhase = "create"          // 工厂创建组件实例的耗时
+	PhaseStart          ProfilePhase = "start"           // 组件启动的耗时
+)
+
+// 一个阶段的时间区间
+type PhaseSpan struct {
+	Phase    ProfilePhase  `json:"phase"`
+	Start    time.Time     `json:"start"`
+	Duration time.Duration `json:"duration"`
+}
+
+// 一个具名组件各阶段的耗时
+type ComponentTiming struct {
+	Path  string          `json:"path"`
+	Type  ComponentTypeID `json:"type"`
+	Deps  []string        `json:"deps"` // 构造时存在的依赖组件的绝对路径
+	Spans []PhaseSpan     `json:"spans"`
+}
+
+// 某个阶段的耗时，未记录该阶段时为0
+func (t ComponentTiming) Phase(phase ProfilePhase) (d time.Duration) {
+	for _, span := range t.Spans {
+		if span.Phase == phase {
+			d += span.Duration
+		}
+	}
+	return
+}
+
+// 组件自身的耗时，即创建与启动的耗时之和，不含等待依赖的耗时
+func (t ComponentTiming) Duration() time.Duration {
+	return t.Phase(PhaseCreate) + t.Phase(PhaseStart)
+}
+
+// 启动耗时报告
+type StartupReport struct {
+	Components           []ComponentTiming `json:"components"` // 按自身耗时从大到小排列
+	CriticalPath         []string          `json:"critical_path"`
+	CriticalPathDuration time.Duration     `json:"critical_path_duration"`
+}
+
+// 组件耗时的记录，同一棵容器树共享
+type profiler struct {
+	mu      sync.Mutex
+	timings map[string]*ComponentTiming
+}
+
+// 开始记录一个组件，组件被重建时会清空之前的记录
+func (p *profiler) begin(path string, typ ComponentTypeID, deps []string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.timings == nil {
+		p.timings = make(map[string]*ComponentTiming)
+	}
+	p.timings[path] = &ComponentTiming{Path: path, Type: typ, Deps: deps}
+}
+
+// 记录组件某个阶段的耗时，同一阶段的记录会被覆盖
+func (p *profiler) record(path string, phase ProfilePhase, start time.Time, d time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	timing, ok := p.timings[path]
+	if !ok {
+		return
+	}
+	span := PhaseSpan{Phase: phase, Start: start, Duration: d}
+	if i := slices.IndexFunc(timing.Spans, func(s PhaseSpan) bool { return s.Phase == phase }); i >= 0 {
+		timing.Spans[i] = span
+		return
+	}
+	timing.Spans = append(timing.Spans, span)
+}
+
+// 记录当前组件某个阶段的耗时，匿名组件的耗时计入其所属组件，不单独记录
+func (c BuildContext) recordPhase(phase ProfilePhase, start time.Time, d time.Duration) {
+	container, ok := c.Container.(*ComponentContainer)
+	if !ok || c.Config.Name == "" {
+		return
+	}
+	container.profiler.record(formatPath(c.GetAbsolutePath()), phase, start, d)
+}
+
+// 组件构造时存在的依赖组件的绝对路径
+func (c *ComponentContainer) depPaths(config ComponentConfig, missingDeps []ComponentName) (paths []string) {
+	base := containerPath(c)
+	for _, dep := range config.Deps {
+		name, _ := parseDep(dep)
+		if !slices.Contains(missingDeps, name) {
+			paths = append(paths, base+"/"+name.String())
+		}
+	}
+	return
+}
+
+// 并行加载一批组件：每个组件在其依赖全部就绪后开始构造，任意组件失败后不再构造新的组件
+func (c *ComponentContainer) loadParallel(ctx context.Context, orders []ComponentName, dag map[ComponentName]set[ComponentName], configMap map[ComponentName]ComponentConfig) error {
+	done := make(map[ComponentName]chan struct{}, len(orders))
+	for _, name := range orders {
+		done[name] = make(chan struct{})
+	}
+	sem := make(chan struct{}, c.parallelism)
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		errs   []error
+		failed bool
+	)
+	for _, name := range orders {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer close(done[name])
+			start := time.Now()
+			for dep := range dag[name] {
+				if ch, ok := done[dep]; ok {
+					<-ch
+				}
+			}
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			wait := time.Since(start)
+
+			mu.Lock()
+			stop := failed
+			mu.Unlock()
+			if stop {
+				return
+			}
+			component, err := c.loadNamedComponent(ctx, configMap[name])
+			if err != nil {
+				c.log().Error("load components failed", "name", name, "error", err)
+				mu.Lock()
+				failed = true
+				errs = append(errs, err)
+				mu.Unlock()
+				return
+			}
+			c.profiler.record(containerPath(c)+"/"+name.String(), PhaseDependencyWait, start, wait)
+			c.mu.Lock()
+			c.components[name] = component
+			c.configs[name] = configMap[name]
+			c.mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// StartupReport 生成容器及其子容器中具名组件的耗时报告，包含按耗时排序的组件与依赖图上的关键路径
+func (c *ComponentContainer) StartupReport() (report StartupReport) {
+	prefix := containerPath(c) + "/"
+	timings := make(map[string]ComponentTiming)
+	c.profiler.mu.Lock()
+	for path, timing := range c.profiler.timings {
+		if strings.HasPrefix(path, prefix) {
+			t := *timing
+			t.Spans = slices.Clone(timing.Spans)
+			timings[path] = t
+		}
+	}
+	c.profiler.mu.Unlock()
+
+	for _, timing := range timings {
+		report.Components = append(report.Components, timing)
+	}
+	slices.SortFunc(report.Components, func(a, b ComponentTiming) int {
+		return cmp.Or(cmp.Compare(b.Duration(), a.Duration()), cmp.Compare(a.Path, b.Path))
+	})
+
+	// 关键路径：沿依赖关系自身耗时之和最大的路径
+	longest := make(map[string]time.Duration)
+	next := make(map[string]string)
+	var visit func(path string) time.Duration
+	visit = func(path string) time.Duration {
+		if d, ok := longest[path]; ok {
+			return d
+		}
+		longest[path] = 0 // 防止环导致无限递归
+		var best time.Duration
+		for _, dep := range timings[path].Deps {
+			if _, ok := timings[dep]; !ok {
+				continue
+			}
+			if d := visit(dep); d > best || next[path] == "" {
+				best, next[path] = d, dep
+			}
+		}
+		longest[path] = best + timings[path].Duration()
+		return longest[path]
+	}
+	var head string
+	for _, timing := range report.Components {
+		if d := visit(timing.Path); head == "" || d > report.CriticalPathDuration {
+			head, report.CriticalPathDuration = timing.Path, d
+		}
+	}
+	for path := head; path != ""; path = next[path] {
+		report.CriticalPath = append(report.CriticalPath, path)
+	}
+	slices.Reverse(report.CriticalPath)
+	return
+}
+
+// 以文本表格输出报告
+func (r StartupReport) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "PATH\tTYPE\tTOTAL\tDEPENDENCY_WAIT\tDECODE\tCREATE\tSTART\n")
+	for _, t := range r.Components {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", t.Path, t.Type, t.Duration(),
+			t.Phase(PhaseDependencyWait), t.Phase(PhaseDecode), t.Phase(PhaseCreate), t.Phase(PhaseStart))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "\ncritical path (%s): %s\n", r.CriticalPathDuration, strings.Join(r.CriticalPath, " -> "))
+	return err
+}
+
+// Chrome trace-event格式中的事件
+type traceEvent struct {
+	Name  string         `json:"name"`
+	Cat   string         `json:"cat,omitempty"`
+	Phase string         `json:"ph"`
+	TS    int64          `json:"ts"` // 微秒
+	Dur   int64          `json:"dur,omitempty"`
+	PID   int            `json:"pid"`
+	TID   int            `json:"tid"`
+	Args  map[string]any `json:"args,omitempty"`
+}
+
+// 以Chrome trace-event JSON格式输出报告，可在chrome://tracing或Perfetto中以火焰图查看，每个组件占一行
+func (r StartupReport) WriteChromeTrace(w io.Writer) error {
+	var origin time.Time
+	for _, t := range r.Components {
+		for _, span := range t.Spans {
+			if origin.IsZero() || span.Start.Before(origin) {
+				origin = span.Start
+			}
+		}
+	}
+	components := slices.Clone(r.Components)
+	slices.SortFunc(components, func(a, b ComponentTiming) int { return cmp.Compare(a.Path, b.Path) })
+
+	events := []traceEvent{}
+	for i, t := range components {
+		tid := i + 1
+		events = append(events, traceEvent{Name: "thread_name", Phase: "M", PID: 1, TID: tid, Args: map[string]any{"name": t.Path}})
+		for _, span := range t.Spans {
+			events = append(events, traceEvent{
+				Name:  string(span.Phase),
+				Cat:   string(t.Type),
+				Phase: "X",
+				TS:    span.Start.Sub(origin).Microseconds(),
+				Dur:   span.Duration.Microseconds(),
+				PID:   1,
+				TID:   tid,
+				Args:  map[string]any{"path": t.Path},
+			})
+		}
+	}
+	return json.NewEncoder(w).Encode(map[string]any{"traceEvents": events, "displayTimeUnit": "ms"})
+}
@@ -0,0 +1,95 @@
+package compcont
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type sleepConfig struct {
+	Sleep time.Duration
+}
+
+func TestStartupReport(t *testing.T) {
+	registry := NewFactoryRegistry()
+	MustRegister(registry, &TypedSimpleComponentFactory[sleepConfig, string]{
+		TypeID: "sleep",
+		CreateInstanceFunc: func(ctx BuildContext, config sleepConfig) (instance string, err error) {
+			time.Sleep(config.Sleep)
+			return
+		},
+	})
+	container := NewComponentContainer(WithFactoryRegistry(registry), WithParallelism(4))
+
+	err := container.LoadNamedComponents([]ComponentConfig{
+		{Name: "db", Type: "sleep", Config: map[string]any{"Sleep": "40ms"}},
+		{Name: "cache", Type: "sleep", Config: map[string]any{"Sleep": "20ms"}},
+		{Name: "api", Type: "sleep", Deps: []ComponentName{"db", "cache"}, Config: map[string]any{"Sleep": "10ms"}},
+	})
+	assert.NoError(t, err)
+
+	report := container.(*ComponentContainer).StartupReport()
+	assert.Equal(t, []string{"/db", "/api"}, report.CriticalPath)
+	assert.GreaterOrEqual(t, report.CriticalPathDuration, 50*time.Millisecond)
+	assert.Equal(t, "/db", report.Components[0].Path)
+	assert.Equal(t, []string{"/db", "/cache"}, report.Components[2].Deps)
+
+	api := report.Components[2]
+	assert.Equal(t, "/api", api.Path)
+	assert.GreaterOrEqual(t, api.Phase(PhaseDependencyWait), 40*time.Millisecond)
+	assert.Greater(t, api.Phase(PhaseDecode), time.Duration(0))
+	assert.GreaterOrEqual(t, api.Phase(PhaseCreate), 10*time.Millisecond)
+
+	var buf bytes.Buffer
+	assert.NoError(t, report.WriteChromeTrace(&buf))
+	var trace struct {
+		TraceEvents []traceEvent `json:"traceEvents"`
+	}
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &trace))
+	assert.Equal(t, "thread_name", trace.TraceEvents[0].Name)
+	assert.Contains(t, trace.TraceEvents, traceEvent{Name: "thread_name", Phase: "M", PID: 1, TID: 1, Args: map[string]any{"name": "/api"}})
+
+	buf.Reset()
+	assert.NoError(t, report.WriteText(&buf))
+	assert.Contains(t, buf.String(), "critical path")
+}
+
+func TestParallelLoad(t *testing.T) {
+	// 互不依赖的组件互相等待对方开始构造，只有并行构造时才能全部创建成功
+	var wg sync.WaitGroup
+	wg.Add(2)
+	all := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(all)
+	}()
+	registry := NewFactoryRegistry()
+	MustRegister(registry, &TypedSimpleComponentFactory[struct{}, string]{
+		TypeID: "barrier",
+		CreateInstanceFunc: func(ctx BuildContext, config struct{}) (instance string, err error) {
+			if ctx.Config.Name == "api" {
+				return
+			}
+			wg.Done()
+			select {
+			case <-all:
+			case <-time.After(time.Second):
+				err = errors.New("not built concurrently")
+			}
+			return
+		},
+	})
+	container := NewComponentContainer(WithFactoryRegistry(registry), WithParallelism(2))
+	err := container.LoadNamedComponents([]ComponentConfig{
+		{Name: "db", Type: "barrier"},
+		{Name: "cache", Type: "barrier"},
+		{Name: "api", Type: "barrier", Deps: []ComponentName{"db", "cache"}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, ComponentName("api"), container.LoadedComponentNames()[2])
+}
@@ -0,0 +1,276 @@
+package compcont
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+)
+
+// 热更新的变更计划，由PlanReconcile计算得到，再交给ApplyReconcile执行
+type ReconcilePlan struct {
+	Added   []ComponentName // 新增的组件，按构建顺序排列
+	Removed []ComponentName // 移除的组件，按销毁顺序排列
+	Changed []ComponentName // 配置发生变化的组件，按构建顺序排列
+	Rebuilt []ComponentName // 需要重建的组件，即变化的组件及其传递依赖方，按构建顺序排列
+	Skipped []ComponentName // 因条件不满足而被跳过加载的组件
+
+	base     map[ComponentName]ComponentConfig // 计算计划时容器中的声明配置，用于检查计划是否过期
+	configs  map[ComponentName]ComponentConfig // 新的声明配置
+	build    []ComponentName                   // 需要构建的组件（新增+重建），按构建顺序排列
+	teardown []ComponentName                   // 需要销毁的旧组件（移除+重建），按销毁顺序排列
+}
+
+// 计划是否不包含任何变更
+func (p ReconcilePlan) Empty() bool {
+	return len(p.Added) == 0 && len(p.Removed) == 0 && len(p.Rebuilt) == 0
+}
+
+// PlanReconcile 对比新的配置集合与容器中已加载组件的配置，计算出最小变更计划，不会修改容器
+func (c *ComponentContainer) PlanReconcile(configs []ComponentConfig) (plan ReconcilePlan, err error) {
+	configMap, err := newConfigMap(configs)
+	if err != nil {
+		return
+	}
+	if plan.Skipped, err = c.conditionEnv().filter(configMap); err != nil {
+		return
+	}
+	inferGroupDeps(c.factoryRegistry, configMap, nil)
+	if err = checkScopes(configMap, func(ComponentName) (cfg ComponentConfig, ok bool) { return }); err != nil {
+		return
+	}
+	skipped := plan.Skipped
+	plan, err = c.planReconcile(configMap, nil)
+	plan.Skipped = skipped
+	return
+}
+
+// RestartComponents 按原配置重建指定的组件及其传递依赖方，失败时回滚
+func (c *ComponentContainer) RestartComponents(names ...ComponentName) (err error) {
+	return c.RestartComponentsContext(c.context.Context(), names...)
+}
+
+// RestartComponentsContext 同RestartComponents，重建的span是ctx中span的子span
+func (c *ComponentContainer) RestartComponentsContext(ctx context.Context, names ...ComponentName) (err error) {
+	c.mu.RLock()
+	configMap := maps.Clone(c.configs)
+	c.mu.RUnlock()
+	for _, name := range names {
+		if _, ok := configMap[name]; !ok {
+			return fmt.Errorf("%w, name: %s", ErrComponentNameNotFound, name)
+		}
+	}
+	plan, err := c.planReconcile(configMap, names)
+	if err != nil {
+		return
+	}
+	return c.applyReconcile(ctx, plan) // 重建不计入热更新次数
+}
+
+// 计算变更计划，forced中的组件即使配置未变也会被重建
+func (c *ComponentContainer) planReconcile(configMap map[ComponentName]ComponentConfig, forced []ComponentName) (plan ReconcilePlan, err error) {
+	c.mu.RLock()
+	base := maps.Clone(c.configs)
+	unmanaged := make(set[ComponentName]) // 通过PutComponent直接放入的组件，不参与热更新
+	for name := range c.components {
+		if _, ok := c.configs[name]; !ok {
+			unmanaged[name] = struct{}{}
+		}
+	}
+	c.mu.RUnlock()
+
+	// 新配置的构建顺序
+	newOrders, err := topologicalSort(configDAG(configMap, unmanaged))
+	if err != nil {
+		return
+	}
+	// 旧配置的构建顺序
+	baseOrders, err := topologicalSort(configDAG(base, unmanaged))
+	if err != nil {
+		return
+	}
+
+	// 计算新增、移除与变化的组件
+	changed := make(set[ComponentName])
+	for _, name := range newOrders {
+		old, ok := base[name]
+		if !ok {
+			plan.Added = append(plan.Added, name)
+			continue
+		}
+		if !reflect.DeepEqual(old, configMap[name]) {
+			changed[name] = struct{}{}
+			plan.Changed = append(plan.Changed, name)
+		}
+	}
+
+	// 变化组件在旧依赖图中的传递依赖方都需要重建，
+	// 可选依赖被新增或移除的组件虽然配置未变，也需要重建以感知依赖的变化
+	dependents := make(map[ComponentName][]ComponentName)
+	queue := append(slices.Collect(maps.Keys(changed)), forced...)
+	for name, cfg := range base {
+		for _, dep := range cfg.Deps {
+			dep, optional := parseDep(dep)
+			dependents[dep] = append(dependents[dep], name)
+			_, existed := base[dep]
+			_, exists := configMap[dep]
+			if _, kept := configMap[name]; kept && optional && existed != exists {
+				queue = append(queue, name)
+			}
+		}
+	}
+	rebuilt := make(set[ComponentName])
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		if _, ok := rebuilt[name]; ok {
+			continue
+		}
+		rebuilt[name] = struct{}{}
+		queue = append(queue, dependents[name]...)
+	}
+
+	for _, name := range newOrders {
+		_, isRebuilt := rebuilt[name]
+		_, existed := base[name]
+		if isRebuilt && existed {
+			plan.Rebuilt = append(plan.Rebuilt, name)
+		}
+		if isRebuilt || !existed {
+			plan.build = append(plan.build, name)
+		}
+	}
+	for _, name := range slices.Backward(baseOrders) {
+		_, isRebuilt := rebuilt[name]
+		_, kept := configMap[name]
+		if !kept {
+			plan.Removed = append(plan.Removed, name)
+		}
+		if !kept || isRebuilt {
+			plan.teardown = append(plan.teardown, name)
+		}
+	}
+
+	plan.base = base
+	plan.configs = configMap
+	return
+}
+
+// ApplyReconcile 执行变更计划：先按构建顺序创建新增与重建的组件，全部成功后再销毁被替换与被移除的旧组件。
+// 任意组件构建失败时，已创建的新组件会被销毁，旧组件原样恢复
+func (c *ComponentContainer) ApplyReconcile(plan ReconcilePlan) (err error) {
+	return c.ApplyReconcileContext(c.context.Context(), plan)
+}
+
+// ApplyReconcileContext 同ApplyReconcile，热更新的span是ctx中span的子span
+func (c *ComponentContainer) ApplyReconcileContext(ctx context.Context, plan ReconcilePlan) (err error) {
+	defer func() { c.metrics.reloaded(containerPath(c), err) }()
+	return c.applyReconcile(ctx, plan)
+}
+
+func (c *ComponentContainer) applyReconcile(parent context.Context, plan ReconcilePlan) (err error) {
+	c.dispatcher.hold() // 持有锁期间的事件在解锁后通知
+	defer c.dispatcher.release()
+	c.reconcileMu.Lock()
+	defer c.reconcileMu.Unlock()
+	if err = parent.Err(); err != nil {
+		return
+	}
+	ctx, span := c.tracer.StartSpan(parent, SpanReconcile, c.spanOptions())
+	defer func() { span.End(err) }()
+
+	c.mu.RLock()
+	stale := !reflect.DeepEqual(plan.base, c.configs)
+	c.mu.RUnlock()
+	if stale {
+		return ErrReconcilePlanStale
+	}
+
+	// 暂存被替换的旧组件，用于回滚与后续销毁
+	olds := make(map[ComponentName]Component)
+	c.mu.RLock()
+	for _, name := range plan.teardown {
+		olds[name] = c.components[name]
+	}
+	c.mu.RUnlock()
+
+	var built []ComponentName
+	for _, name := range plan.build {
+		component, loadErr := c.loadNamedComponent(ctx, plan.configs[name])
+		if loadErr != nil {
+			err = fmt.Errorf("reconcile failed, build component %s: %w", name, loadErr)
+			if rollbackErr := c.rollbackReconcile(ctx, built, olds); rollbackErr != nil {
+				err = errors.Join(err, fmt.Errorf("rollback failed: %w", rollbackErr))
+			}
+			c.log().Error("reconcile failed", "error", err)
+			return
+		}
+		c.mu.Lock()
+		c.components[name] = component
+		c.mu.Unlock()
+		built = append(built, name)
+	}
+
+	c.mu.Lock()
+	for _, name := range plan.Removed {
+		delete(c.components, name)
+	}
+	c.configs = maps.Clone(plan.configs)
+	c.mu.Unlock()
+	c.log().Info("reconcile applied", "added", plan.Added, "removed", plan.Removed, "rebuilt", plan.Rebuilt)
+
+	// 新组件全部就绪后再停止并销毁旧组件，容器已启动时启动新组件
+	c.lifecycleMu.Lock()
+	defer c.lifecycleMu.Unlock()
+	errs := []error{c.stopForTeardown(ctx, plan.teardown, olds)}
+	for _, name := range plan.teardown {
+		if destroyErr := c.destroyComponentWithin(ctx, olds[name]); destroyErr != nil {
+			errs = append(errs, fmt.Errorf("destroy component %s: %w", name, destroyErr))
+		}
+	}
+	if c.running.Load() {
+		errs = append(errs, c.start(ctx))
+	}
+	return errors.Join(errs...)
+}
+
+// 回滚热更新：逆序销毁已创建的新组件，并恢复旧组件
+func (c *ComponentContainer) rollbackReconcile(ctx context.Context, built []ComponentName, olds map[ComponentName]Component) error {
+	var errs []error
+	for _, name := range slices.Backward(built) {
+		c.mu.Lock()
+		component := c.components[name]
+		if old, ok := olds[name]; ok {
+			c.components[name] = old
+		} else {
+			delete(c.components, name)
+		}
+		c.mu.Unlock()
+		if err := c.destroyComponentWithin(ctx, component); err != nil {
+			errs = append(errs, fmt.Errorf("destroy component %s: %w", name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// 根据声明配置构建依赖图，unmanaged中的组件已存在于容器中，不参与排序
+func configDAG(configMap map[ComponentName]ComponentConfig, unmanaged set[ComponentName]) map[ComponentName]set[ComponentName] {
+	dag := make(map[ComponentName]set[ComponentName])
+	for name, cfg := range configMap {
+		dag[name] = make(set[ComponentName])
+		for _, dep := range cfg.Deps {
+			dep, optional := parseDep(dep)
+			if _, ok := unmanaged[dep]; ok {
+				continue
+			}
+			// 不存在的可选依赖不参与排序
+			if _, ok := configMap[dep]; optional && !ok {
+				continue
+			}
+			dag[name][dep] = struct{}{}
+		}
+	}
+	return dag
+}
@@ -0,0 +1,117 @@
+package compcont
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type reconcileRecorder struct {
+	created   []string
+	destroyed []string
+}
+
+type reconcileConfig struct {
+	Value string `ccf:"value"`
+	Fail  bool   `ccf:"fail"`
+}
+
+func newReconcileFactory(r *reconcileRecorder) IComponentFactory {
+	return &TypedSimpleComponentFactory[reconcileConfig, string]{
+		TypeID: "reconcile",
+		CreateInstanceFunc: func(ctx BuildContext, config reconcileConfig) (instance string, err error) {
+			if config.Fail {
+				err = errors.New("create failed")
+				return
+			}
+			instance = ctx.Config.Name.String() + ":" + config.Value
+			r.created = append(r.created, instance)
+			return
+		},
+		DestroyInstanceFunc: func(ctx BuildContext, instance string) (err error) {
+			r.destroyed = append(r.destroyed, instance)
+			return
+		},
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	recorder := &reconcileRecorder{}
+	registry := NewFactoryRegistry()
+	MustRegister(registry, newReconcileFactory(recorder))
+	container := NewComponentContainer(WithFactoryRegistry(registry)).(*ComponentContainer)
+
+	err := container.LoadNamedComponents([]ComponentConfig{
+		{Name: "a", Type: "reconcile", Config: reconcileConfig{Value: "1"}},
+		{Name: "b", Type: "reconcile", Deps: []ComponentName{"a"}, Config: reconcileConfig{Value: "1"}},
+		{Name: "c", Type: "reconcile", Config: reconcileConfig{Value: "1"}},
+		{Name: "d", Type: "reconcile", Config: reconcileConfig{Value: "1"}},
+	})
+	assert.NoError(t, err)
+	recorder.created = nil
+
+	plan, err := container.PlanReconcile([]ComponentConfig{
+		{Name: "a", Type: "reconcile", Config: reconcileConfig{Value: "2"}},
+		{Name: "b", Type: "reconcile", Deps: []ComponentName{"a"}, Config: reconcileConfig{Value: "1"}},
+		{Name: "c", Type: "reconcile", Config: reconcileConfig{Value: "1"}},
+		{Name: "e", Type: "reconcile", Config: reconcileConfig{Value: "1"}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []ComponentName{"e"}, plan.Added)
+	assert.Equal(t, []ComponentName{"d"}, plan.Removed)
+	assert.Equal(t, []ComponentName{"a"}, plan.Changed)
+	assert.Equal(t, []ComponentName{"a", "b"}, plan.Rebuilt)
+
+	assert.NoError(t, container.ApplyReconcile(plan))
+	assert.ElementsMatch(t, []string{"a:2", "b:1", "e:1"}, recorder.created)
+	assert.ElementsMatch(t, []string{"a:1", "b:1", "d:1"}, recorder.destroyed)
+	assert.ErrorIs(t, container.ApplyReconcile(plan), ErrReconcilePlanStale)
+
+	b, err := GetComponent[string](container, "b")
+	assert.NoError(t, err)
+	assert.Equal(t, "b:1", b.Instance)
+	_, err = container.GetComponent("d")
+	assert.ErrorIs(t, err, ErrComponentNameNotFound)
+
+	// 构建失败时回滚
+	recorder.created, recorder.destroyed = nil, nil
+	plan, err = container.PlanReconcile([]ComponentConfig{
+		{Name: "a", Type: "reconcile", Config: reconcileConfig{Value: "3"}},
+		{Name: "b", Type: "reconcile", Deps: []ComponentName{"a"}, Config: reconcileConfig{Fail: true}},
+		{Name: "c", Type: "reconcile", Config: reconcileConfig{Value: "1"}},
+		{Name: "e", Type: "reconcile", Config: reconcileConfig{Value: "1"}},
+	})
+	assert.NoError(t, err)
+	assert.Error(t, container.ApplyReconcile(plan))
+	assert.Equal(t, []string{"a:3"}, recorder.created)
+	assert.Equal(t, []string{"a:3"}, recorder.destroyed)
+
+	a, err := GetComponent[string](container, "a")
+	assert.NoError(t, err)
+	assert.Equal(t, "a:2", a.Instance)
+}
+
+func TestReconcileOptionalDeps(t *testing.T) {
+	registry := NewFactoryRegistry()
+	MustRegister(registry, newReconcileFactory(&reconcileRecorder{}))
+	container := NewComponentContainer(WithFactoryRegistry(registry)).(*ComponentContainer)
+
+	server := ComponentConfig{Name: "server", Type: "reconcile", Deps: []ComponentName{"tracer?"}}
+	assert.NoError(t, container.LoadNamedComponents([]ComponentConfig{server}))
+	component, err := container.GetComponent("server")
+	assert.NoError(t, err)
+	assert.Equal(t, []ComponentName{"tracer"}, component.BuildContext.MissingDeps)
+	assert.False(t, component.BuildContext.HasDep("tracer"))
+
+	// 可选依赖出现后，依赖方需要重建
+	plan, err := container.PlanReconcile([]ComponentConfig{server, {Name: "tracer", Type: "reconcile"}})
+	assert.NoError(t, err)
+	assert.Equal(t, []ComponentName{"tracer"}, plan.Added)
+	assert.Equal(t, []ComponentName{"server"}, plan.Rebuilt)
+	assert.NoError(t, container.ApplyReconcile(plan))
+	component, err = container.GetComponent("server")
+	assert.NoError(t, err)
+	assert.True(t, component.BuildContext.HasDep("tracer"))
+	assert.Equal(t, []ComponentName{"tracer", "server"}, container.LoadedComponentNames())
+}
@@ -0,0 +1,160 @@
+package compcont
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// 可从字符串解析的时长，如"500ms"、"2s"，用于组件的声明配置
+type Duration time.Duration
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	v, err := time.ParseDuration(string(text))
+	if err != nil {
+		return fmt.Errorf("%w, invalid duration %q", ErrComponentConfigInvalid, text)
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// 组件创建失败时的重试配置
+type RetryConfig struct {
+	MaxAttempts    int      `json:"max_attempts" yaml:"max_attempts"`       // 最大尝试次数，包含首次创建，不填或为1时不重试
+	InitialBackoff Duration `json:"initial_backoff" yaml:"initial_backoff"` // 首次重试前的等待时间，不填为100ms
+	MaxBackoff     Duration `json:"max_backoff" yaml:"max_backoff"`         // 等待时间的上限，不填为10s
+	Multiplier     float64  `json:"multiplier" yaml:"multiplier"`           // 每次重试等待时间的增长倍数，不填为2
+	Jitter         *float64 `json:"jitter" yaml:"jitter"`                   // 等待时间的随机抖动比例，取值[0,1]，不填为0.2
+}
+
+// 第attempt次尝试失败后的等待时间
+func (c RetryConfig) backoff(attempt int) time.Duration {
+	initial, maxBackoff, multiplier, jitter := time.Duration(c.InitialBackoff), time.Duration(c.MaxBackoff), c.Multiplier, 0.2
+	if initial <= 0 {
+		initial = 100 * time.Millisecond
+	}
+	if maxBackoff <= 0 {
+		maxBackoff = 10 * time.Second
+	}
+	if multiplier <= 0 {
+		multiplier = 2
+	}
+	if c.Jitter != nil {
+		jitter = min(max(*c.Jitter, 0), 1)
+	}
+	d := min(float64(initial)*math.Pow(multiplier, float64(attempt-1)), float64(maxBackoff))
+	d *= 1 + jitter*(2*rand.Float64()-1)
+	return time.Duration(d)
+}
+
+// 错误可实现该接口以声明自身是否可重试，优先级高于工厂与容器上的分类
+type IRetryableError interface {
+	error
+	Retryable() bool
+}
+
+// 工厂可实现该接口对创建失败的错误进行分类
+type IRetryClassifier interface {
+	IsRetryable(err error) bool
+}
+
+type retryableError struct {
+	err       error
+	retryable bool
+}
+
+func (e retryableError) Error() string   { return e.err.Error() }
+func (e retryableError) Unwrap() error   { return e.err }
+func (e retryableError) Retryable() bool { return e.retryable }
+
+// RetryableError 将错误标记为可重试
+func RetryableError(err error) error {
+	return retryableError{err: err, retryable: true}
+}
+
+// PermanentError 将错误标记为不可重试
+func PermanentError(err error) error {
+	return retryableError{err: err, retryable: false}
+}
+
+// 重试后仍然失败的错误，记录了尝试次数
+type RetryError struct {
+	Attempts int
+	Err      error
+}
+
+func (e *RetryError) Error() string {
+	return fmt.Sprintf("create instance failed after %d attempts: %s", e.Attempts, e.Err)
+}
+
+func (e *RetryError) Unwrap() error {
+	return e.Err
+}
+
+// 设置容器中可重试的错误，创建失败的错误通过errors.Is匹配其中任意一个时才会重试，
+// 未设置时除context取消、配置无效与被订阅者否决外的错误均可重试，未设置时继承父容器的设置
+func WithRetryableErrors(errs ...error) Option {
+	return func(o *options) {
+		o.retryableErrors = errs
+	}
+}
+
+// 判断创建失败的错误是否可以重试
+func (c *ComponentContainer) retryable(factory IComponentFactory, err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	var r IRetryableError
+	if errors.As(err, &r) {
+		return r.Retryable()
+	}
+	if classifier, ok := factory.(IRetryClassifier); ok {
+		return classifier.IsRetryable(err)
+	}
+	if len(c.retryableErrors) == 0 { // 配置无效与被否决的创建重试也不会成功
+		return !errors.Is(err, ErrComponentConfigInvalid) && !errors.Is(err, ErrComponentVetoed)
+	}
+	for _, target := range c.retryableErrors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// 按组件的重试配置创建实例，每次失败的尝试都会销毁其中加载的匿名组件，等待重试期间ctx被取消时立即返回
+func (c *ComponentContainer) createInstance(factory IComponentFactory, ctx BuildContext) (instance any, attempts int, err error) {
+	policy := ctx.Config.Retry
+	for attempts = 1; ; attempts++ {
+		start := time.Now()
+		if instance, err = factory.CreateInstance(ctx, ctx.Config.Config); err == nil {
+			return
+		}
+		if destroyErr := c.destroyOwned(ctx.Context(), ctx.owned); destroyErr != nil {
+			ctx.Logger().Error("destroy anonymous components of failed attempt", "attempt", attempts, "error", destroyErr)
+		}
+		if attempts >= policy.MaxAttempts || !c.retryable(factory, err) {
+			break
+		}
+		c.emit(&Event{Type: EventCreateRetrying, Time: time.Now(), Context: ctx, Duration: time.Since(start), Err: err, Attempt: attempts})
+		timer := time.NewTimer(policy.backoff(attempts))
+		select {
+		case <-ctx.Context().Done():
+			timer.Stop()
+			err = errors.Join(err, ctx.Context().Err())
+			return instance, attempts, &RetryError{Attempts: attempts, Err: err}
+		case <-timer.C:
+		}
+	}
+	if attempts > 1 {
+		err = &RetryError{Attempts: attempts, Err: err}
+	}
+	return
+}
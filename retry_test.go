@@ -0,0 +1,119 @@
+package compcont
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var errNotReady = errors.New("dns not ready")
+
+func TestRetry(t *testing.T) {
+	failures := map[ComponentName]int{}
+	registry := NewFactoryRegistry()
+	MustRegister(registry, &TypedSimpleComponentFactory[struct{}, string]{
+		TypeID: "remote",
+		CreateInstanceFunc: func(ctx BuildContext, config struct{}) (instance string, err error) {
+			if failures[ctx.Config.Name] > 0 {
+				failures[ctx.Config.Name]--
+				if ctx.Config.Name == "invalid" {
+					return "", PermanentError(errors.New("bad credentials"))
+				}
+				return "", errNotReady
+			}
+			return "ok", nil
+		},
+	})
+	container := NewComponentContainer(WithFactoryRegistry(registry)).(*ComponentContainer)
+	var events []Event
+	container.Subscribe(func(e *Event) error {
+		events = append(events, *e)
+		return nil
+	})
+	retry := RetryConfig{MaxAttempts: 3, InitialBackoff: Duration(time.Millisecond)}
+
+	// 重试后成功
+	failures["db"] = 2
+	err := container.LoadNamedComponents([]ComponentConfig{{Name: "db", Type: "remote", Retry: retry}})
+	assert.NoError(t, err)
+	assert.Equal(t, EventAfterCreate, events[len(events)-1].Type)
+	assert.Equal(t, 3, events[len(events)-1].Attempt)
+	assert.Equal(t, EventCreateRetrying, events[len(events)-2].Type)
+	assert.Equal(t, 2, events[len(events)-2].Attempt)
+
+	// 超过最大尝试次数
+	failures["cache"] = 5
+	err = container.LoadNamedComponents([]ComponentConfig{{Name: "cache", Type: "remote", Retry: retry}})
+	var retryErr *RetryError
+	assert.ErrorAs(t, err, &retryErr)
+	assert.Equal(t, 3, retryErr.Attempts)
+	assert.ErrorIs(t, err, errNotReady)
+	assert.Equal(t, 3, events[len(events)-1].Attempt)
+
+	// 不可重试的错误不重试
+	failures["invalid"] = 5
+	err = container.LoadNamedComponents([]ComponentConfig{{Name: "invalid", Type: "remote", Retry: retry}})
+	assert.ErrorContains(t, err, "bad credentials")
+	assert.Equal(t, 4, failures["invalid"])
+
+	// 配置无效与被否决的错误默认不重试
+	MustRegister(registry, &TypedSimpleComponentFactory[struct{}, string]{
+		TypeID: "gateway",
+		CreateInstanceFunc: func(ctx BuildContext, config struct{}) (instance string, err error) {
+			_, err = ctx.LoadAnonymousComponent(ComponentConfig{Type: "remote"})
+			return
+		},
+	})
+	container.Subscribe(func(e *Event) error {
+		if e.Type == EventBeforeCreate && e.Context.Config.Type == "remote" && e.Context.Config.Name == "" {
+			return errors.New("anonymous remote forbidden")
+		}
+		return nil
+	})
+	count := len(events)
+	err = container.LoadNamedComponents([]ComponentConfig{{Name: "bad", Type: "remote", Config: map[string]any{"host": "x"}, Retry: retry}})
+	assert.ErrorIs(t, err, ErrComponentConfigInvalid)
+	err = container.LoadNamedComponents([]ComponentConfig{{Name: "gateway", Type: "gateway", Retry: retry}})
+	assert.ErrorIs(t, err, ErrComponentVetoed)
+	for _, e := range events[count:] {
+		assert.NotEqual(t, EventCreateRetrying, e.Type)
+	}
+
+	// 只重试匹配的错误
+	strict := NewComponentContainer(WithFactoryRegistry(registry), WithRetryableErrors(context.DeadlineExceeded))
+	failures["queue"] = 1
+	err = strict.LoadNamedComponents([]ComponentConfig{{Name: "queue", Type: "remote", Retry: retry}})
+	assert.ErrorIs(t, err, errNotReady)
+
+	// 等待重试期间ctx被取消
+	failures["broker"] = 5
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	slow := RetryConfig{MaxAttempts: 5, InitialBackoff: Duration(time.Minute)}
+	begin := time.Now()
+	err = container.LoadNamedComponentsContext(ctx, []ComponentConfig{{Name: "broker", Type: "remote", Retry: slow}})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(begin), time.Second)
+}
+
+func TestRetryConfigDecode(t *testing.T) {
+	configs, err := ParseConfigs([]byte(`
+- name: db
+  type: remote
+  retry:
+    max_attempts: 5
+    initial_backoff: 200ms
+    jitter: 0
+`), "yaml")
+	assert.NoError(t, err)
+	assert.Equal(t, Duration(200*time.Millisecond), configs[0].Retry.InitialBackoff)
+	assert.Equal(t, 200*time.Millisecond, configs[0].Retry.backoff(1))
+	assert.Equal(t, 800*time.Millisecond, configs[0].Retry.backoff(3))
+
+	configs, err = ParseConfigs([]byte(`[{"name": "db", "type": "remote", "retry": {"max_backoff": "1s"}}]`), "json")
+	assert.NoError(t, err)
+	assert.Equal(t, Duration(time.Second), configs[0].Retry.MaxBackoff)
+}
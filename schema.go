@@ -0,0 +1,173 @@
+package compcont
+
+import (
+	"cmp"
+	"encoding"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+)
+
+// 生成的JSON Schema所遵循的规范版本
+const JSONSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// 组件工厂的元数据
+type FactoryInfo struct {
+	Type         ComponentTypeID `json:"type"`
+	Description  string          `json:"description,omitempty"`
+	ConfigType   string          `json:"config_type,omitempty"`   // 组件配置的Go类型
+	InstanceType string          `json:"instance_type,omitempty"` // 组件实例的Go类型
+	ConfigSchema map[string]any  `json:"config_schema,omitempty"` // 组件配置的JSON Schema
+}
+
+// 组件工厂可实现该接口以提供元数据，用于命令行工具与配置文件的JSON Schema
+type IFactoryDescriber interface {
+	Describe() FactoryInfo
+}
+
+// 组件工厂可实现该接口，在不创建实例的情况下校验组件配置
+type IConfigValidator interface {
+	ValidateConfig(config any) error
+}
+
+// 获取组件工厂的元数据，未实现IFactoryDescriber的工厂只有类型
+func DescribeFactory(f IComponentFactory) (info FactoryInfo) {
+	if describer, ok := f.(IFactoryDescriber); ok {
+		info = describer.Describe()
+	}
+	info.Type = f.Type()
+	return
+}
+
+// 获取注册器中所有组件工厂的元数据，按类型排序
+func DescribeFactories(registry IFactoryRegistry) (infos []FactoryInfo, err error) {
+	for _, t := range registry.RegisteredComponentTypes() {
+		var f IComponentFactory
+		if f, err = registry.GetFactory(t); err != nil {
+			return
+		}
+		infos = append(infos, DescribeFactory(f))
+	}
+	slices.SortFunc(infos, func(a, b FactoryInfo) int { return cmp.Compare(a.Type, b.Type) })
+	return
+}
+
+// ConfigSchema 根据组件配置的Go类型生成JSON Schema，字段名与组件配置的解码规则一致，取自ccf标签
+func ConfigSchema(t reflect.Type) map[string]any {
+	return typeSchema(t, ConfigFieldTagName, make(set[reflect.Type]))
+}
+
+// ConfigFileSchema 生成组件配置文件的JSON Schema，组件的config字段按type对应工厂的配置Schema校验
+func ConfigFileSchema(registry IFactoryRegistry) (schema map[string]any, err error) {
+	infos, err := DescribeFactories(registry)
+	if err != nil {
+		return
+	}
+	component := typeSchema(reflect.TypeFor[ComponentConfig](), "json", make(set[reflect.Type]))
+	properties := component["properties"].(map[string]any)
+	types := make([]string, 0, len(infos))
+	var rules []any
+	for _, info := range infos {
+		types = append(types, info.Type.String())
+		if info.ConfigSchema != nil {
+			rules = append(rules, map[string]any{
+				"if":   map[string]any{"properties": map[string]any{"type": map[string]any{"const": info.Type}}, "required": []string{"type"}},
+				"then": map[string]any{"properties": map[string]any{"config": info.ConfigSchema}},
+			})
+		}
+	}
+	properties["type"] = map[string]any{"type": "string", "enum": types}
+	if len(rules) > 0 {
+		component["allOf"] = rules
+	}
+	schema = map[string]any{
+		"$schema": JSONSchemaDraft,
+		"title":   "compcont component configs",
+		"type":    "array",
+		"items":   component,
+	}
+	return
+}
+
+var (
+	textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+	timeType            = reflect.TypeFor[time.Time]()
+	timeDurationType    = reflect.TypeFor[time.Duration]()
+)
+
+// 生成类型的JSON Schema，tag为决定字段名的结构体标签，seen用于防止递归类型无限展开
+func typeSchema(t reflect.Type, tag string, seen set[reflect.Type]) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch {
+	case t == timeType:
+		return map[string]any{"type": "string", "format": "date-time"}
+	case t == timeDurationType:
+		return map[string]any{"type": []string{"string", "integer"}}
+	case reflect.PointerTo(t).Implements(textUnmarshalerType):
+		return map[string]any{"type": "string"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.String:
+		if t == reflect.TypeFor[ComponentScope]() {
+			return map[string]any{"type": "string", "enum": []ComponentScope{ScopeSingleton, ScopeTransient, ScopeScoped}}
+		}
+		return map[string]any{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": typeSchema(t.Elem(), tag, seen)}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": typeSchema(t.Elem(), tag, seen)}
+	case reflect.Struct:
+		if _, ok := seen[t]; ok {
+			return map[string]any{"type": "object"}
+		}
+		seen[t] = struct{}{}
+		defer delete(seen, t)
+		properties := make(map[string]any)
+		structFields(t, tag, seen, properties)
+		schema := map[string]any{"type": "object", "properties": properties}
+		if tag == "json" { // 配置文件以DisallowUnknownFields解码
+			schema["additionalProperties"] = false
+		}
+		return schema
+	default: // interface等无法确定结构的类型
+		return map[string]any{}
+	}
+}
+
+// 将结构体的字段写入properties，嵌入的结构体按标签的squash或JSON的展开规则合并
+func structFields(t reflect.Type, tag string, seen set[reflect.Type], properties map[string]any) {
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, opts, _ := strings.Cut(field.Tag.Get(tag), ",")
+		if name == "-" {
+			continue
+		}
+		fieldType := field.Type
+		for fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+		squash := slices.Contains(strings.Split(opts, ","), "squash") || (tag == "json" && field.Anonymous && name == "")
+		if squash && fieldType.Kind() == reflect.Struct {
+			structFields(fieldType, tag, seen, properties)
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		properties[name] = typeSchema(field.Type, tag, seen)
+	}
+}
@@ -0,0 +1,170 @@
+package compcont
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// 组件的作用域
+type ComponentScope string
+
+const (
+	ScopeSingleton ComponentScope = "singleton" // 容器内唯一实例，默认作用域
+	ScopeTransient ComponentScope = "transient" // 每次GetComponent都通过工厂创建新实例，调用方用完后需通过DestroyComponent销毁
+	ScopeScoped    ComponentScope = "scoped"    // 每个子作用域内唯一实例，作用域结束时销毁
+)
+
+func (s ComponentScope) String() string {
+	if s == "" {
+		return string(ScopeSingleton)
+	}
+	return string(s)
+}
+
+func (s ComponentScope) Validate() bool {
+	switch s {
+	case "", ScopeSingleton, ScopeTransient, ScopeScoped:
+		return true
+	}
+	return false
+}
+
+// 是否为容器内唯一实例
+func (s ComponentScope) isSingleton() bool {
+	return s == "" || s == ScopeSingleton
+}
+
+// 校验组件的作用域，单例组件不能依赖scoped组件，否则会持有某个作用域的实例
+func checkScopes(configMap map[ComponentName]ComponentConfig, lookup func(name ComponentName) (ComponentConfig, bool)) error {
+	for name, cfg := range configMap {
+		if !cfg.Scope.Validate() {
+			return fmt.Errorf("%w, name: %s, unknown scope %s", ErrComponentConfigInvalid, name, cfg.Scope)
+		}
+		if !cfg.Scope.isSingleton() {
+			continue
+		}
+		for _, dep := range cfg.Deps {
+			dep, _ := parseDep(dep)
+			depCfg, ok := configMap[dep]
+			if !ok {
+				depCfg, ok = lookup(dep)
+			}
+			if ok && depCfg.Scope == ScopeScoped {
+				return fmt.Errorf("%w, singleton component %s depends on scoped component %s", ErrComponentScopeMismatch, name, dep)
+			}
+		}
+	}
+	return nil
+}
+
+// 判断组件是否存在，作用域容器还会查找其所属的容器
+func (c *ComponentContainer) hasComponent(name ComponentName) bool {
+	c.mu.RLock()
+	_, ok := c.components[name]
+	c.mu.RUnlock()
+	if !ok && c.scopeOf != nil {
+		return c.scopeOf.hasComponent(name)
+	}
+	return ok
+}
+
+// 获取声明配置，作用域容器查找其所属的容器
+func (c *ComponentContainer) declaredConfig(name ComponentName) (cfg ComponentConfig, ok bool) {
+	c.mu.RLock()
+	cfg, ok = c.configs[name]
+	c.mu.RUnlock()
+	if !ok && c.scopeOf != nil {
+		return c.scopeOf.declaredConfig(name)
+	}
+	return
+}
+
+// 根据组件的作用域获取组件实例
+func (c *ComponentContainer) getScopedComponent(name ComponentName, cfg ComponentConfig) (component Component, err error) {
+	switch cfg.Scope {
+	case ScopeTransient:
+		if err = c.checkScopedDeps(name, cfg); err != nil {
+			return
+		}
+		return c.MustLoadComponent(cfg)
+	case ScopeScoped:
+		if c.scopeOf == nil {
+			err = fmt.Errorf("%w, name: %s", ErrComponentScopeRequired, name)
+			return
+		}
+		if component, err = c.MustLoadComponent(cfg); err != nil {
+			return
+		}
+		c.mu.Lock()
+		if existing, ok := c.components[name]; ok { // 并发创建时以先放入的实例为准
+			c.mu.Unlock()
+			return existing, c.destroyComponent(component)
+		}
+		c.components[name] = component
+		c.scopedNames = append(c.scopedNames, name)
+		c.mu.Unlock()
+		return
+	}
+	return c.scopeOf.GetComponent(name)
+}
+
+// 作用域外获取的瞬态组件不能依赖scoped组件，否则无法得到依赖的实例
+func (c *ComponentContainer) checkScopedDeps(name ComponentName, cfg ComponentConfig) error {
+	if c.scopeOf != nil {
+		return nil
+	}
+	for _, dep := range cfg.Deps {
+		dep, _ := parseDep(dep)
+		if depCfg, ok := c.declaredConfig(dep); ok && depCfg.Scope == ScopeScoped {
+			return fmt.Errorf("%w, name: %s, depends on scoped component %s", ErrComponentScopeRequired, name, dep)
+		}
+	}
+	return nil
+}
+
+// NewScope 创建一个子作用域，作用域内的scoped组件各自拥有独立的实例，单例组件与所属容器共享。
+// 作用域与所属容器处于容器树的同一位置，作用域结束时需调用CloseScope销毁其中的实例
+func (c *ComponentContainer) NewScope() *ComponentContainer {
+	return &ComponentContainer{
+		context:         c.context,
+		parent:          c.parent,
+		factoryRegistry: c.factoryRegistry,
+		components:      make(map[ComponentName]Component),
+		configs:         make(map[ComponentName]ComponentConfig),
+		profiles:        c.profiles,
+		scopeOf:         c,
+		events:          c.events,
+		dispatcher:      c.dispatcher,
+		logger:          c.logger,
+		parallelism:     c.parallelism,
+		profiler:        c.profiler,
+		metrics:         c.metrics,
+		tracer:          c.tracer,
+		retryableErrors: c.retryableErrors,
+	}
+}
+
+// CloseScope 按创建的逆序销毁作用域内的scoped组件实例
+func (c *ComponentContainer) CloseScope() error {
+	if c.scopeOf == nil {
+		return fmt.Errorf("container is not a scope")
+	}
+	c.mu.Lock()
+	names := c.scopedNames
+	c.scopedNames = nil
+	components := make([]Component, 0, len(names))
+	for _, name := range names {
+		components = append(components, c.components[name])
+		delete(c.components, name)
+	}
+	c.mu.Unlock()
+
+	var errs []error
+	for i, component := range slices.Backward(components) {
+		if err := c.destroyComponent(component); err != nil {
+			errs = append(errs, fmt.Errorf("destroy component %s: %w", names[i], err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,89 @@
+package compcont
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestComponentScopes(t *testing.T) {
+	recorder := &reconcileRecorder{}
+	registry := NewFactoryRegistry()
+	MustRegister(registry, newReconcileFactory(recorder))
+	container := NewComponentContainer(WithFactoryRegistry(registry)).(*ComponentContainer)
+
+	err := container.LoadNamedComponents([]ComponentConfig{
+		{Name: "config", Type: "reconcile", Config: reconcileConfig{Value: "s"}},
+		{Name: "request", Type: "reconcile", Scope: ScopeScoped, Deps: []ComponentName{"config"}, Config: reconcileConfig{Value: "r"}},
+		{Name: "handler", Type: "reconcile", Scope: ScopeTransient, Deps: []ComponentName{"request"}, Config: reconcileConfig{Value: "t"}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"config:s"}, recorder.created)
+
+	// scoped组件及依赖scoped组件的瞬态组件只能在作用域内获取
+	_, err = container.GetComponent("request")
+	assert.ErrorIs(t, err, ErrComponentScopeRequired)
+	_, err = container.GetComponent("handler")
+	assert.ErrorIs(t, err, ErrComponentScopeRequired)
+	assert.Equal(t, []string{"config:s"}, recorder.created)
+
+	scope1, scope2 := container.NewScope(), container.NewScope()
+
+	// 瞬态组件每次获取都创建新实例，由调用方销毁
+	h1, err := scope1.GetComponent("handler")
+	assert.NoError(t, err)
+	h2, err := scope1.GetComponent("handler")
+	assert.NoError(t, err)
+	assert.NotSame(t, h1.BuildContext.Mount, h2.BuildContext.Mount)
+	assert.Equal(t, []string{"config:s", "handler:t", "handler:t"}, recorder.created)
+	assert.NoError(t, scope1.DestroyComponent(h1))
+	assert.NoError(t, scope1.DestroyComponent(h2))
+	assert.Equal(t, []string{"handler:t", "handler:t"}, recorder.destroyed)
+	recorder.destroyed = nil
+
+	r1, err := scope1.GetComponent("request")
+	assert.NoError(t, err)
+	r1Again, err := scope1.GetComponent("request")
+	assert.NoError(t, err)
+	assert.Same(t, r1.BuildContext.Mount, r1Again.BuildContext.Mount)
+	r2, err := scope2.GetComponent("request")
+	assert.NoError(t, err)
+	assert.NotSame(t, r1.BuildContext.Mount, r2.BuildContext.Mount)
+
+	s1, err := scope1.GetComponent("config")
+	assert.NoError(t, err)
+	s, err := container.GetComponent("config")
+	assert.NoError(t, err)
+	assert.Same(t, s.BuildContext.Mount, s1.BuildContext.Mount)
+
+	assert.NoError(t, scope1.CloseScope())
+	assert.Equal(t, []string{"request:r"}, recorder.destroyed)
+
+	// 单例组件不能依赖scoped组件
+	err = container.LoadNamedComponents([]ComponentConfig{
+		{Name: "service", Type: "reconcile", Deps: []ComponentName{"request"}},
+	})
+	assert.ErrorIs(t, err, ErrComponentScopeMismatch)
+}
+
+func TestTransientNotLive(t *testing.T) {
+	metrics := NewMemoryMetrics()
+	registry := NewFactoryRegistry()
+	MustRegister(registry, newReconcileFactory(&reconcileRecorder{}))
+	container := NewComponentContainer(WithFactoryRegistry(registry), WithMetrics(metrics)).(*ComponentContainer)
+	assert.NoError(t, container.LoadNamedComponents([]ComponentConfig{
+		{Name: "config", Type: "reconcile"},
+		{Name: "handler", Type: "reconcile", Scope: ScopeTransient, Deps: []ComponentName{"config"}},
+	}))
+
+	// 反复获取瞬态组件不会改变存活数量，也不会出现在启动耗时中
+	live := map[string]string{"type": "reconcile", "path": "/config"}
+	for range 3 {
+		h, err := container.GetComponent("handler")
+		assert.NoError(t, err)
+		assert.Equal(t, 1.0, metrics.Value(MetricLiveComponents, live))
+		assert.NoError(t, container.DestroyComponent(h))
+	}
+	assert.Equal(t, 1.0, metrics.Value(MetricLiveComponents, live))
+	assert.Len(t, container.StartupReport().Components, 1)
+}
@@ -0,0 +1,181 @@
+package compcont
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+type selectorOperator string
+
+const (
+	selectorOpEquals       selectorOperator = "="
+	selectorOpNotEquals    selectorOperator = "!="
+	selectorOpIn           selectorOperator = "in"
+	selectorOpNotIn        selectorOperator = "notin"
+	selectorOpExists       selectorOperator = "exists"
+	selectorOpDoesNotExist selectorOperator = "!"
+)
+
+// 单个标签匹配条件
+type selectorRequirement struct {
+	key    string
+	op     selectorOperator
+	values []string
+}
+
+func (r selectorRequirement) matches(labels map[string]string) bool {
+	value, ok := labels[r.key]
+	switch r.op {
+	case selectorOpEquals, selectorOpIn:
+		return ok && slices.Contains(r.values, value)
+	case selectorOpNotEquals, selectorOpNotIn:
+		return !ok || !slices.Contains(r.values, value)
+	case selectorOpExists:
+		return ok
+	case selectorOpDoesNotExist:
+		return !ok
+	}
+	return false
+}
+
+func (r selectorRequirement) String() string {
+	switch r.op {
+	case selectorOpExists:
+		return r.key
+	case selectorOpDoesNotExist:
+		return "!" + r.key
+	case selectorOpIn, selectorOpNotIn:
+		return fmt.Sprintf("%s %s (%s)", r.key, r.op, strings.Join(r.values, ","))
+	}
+	return r.key + string(r.op) + r.values[0]
+}
+
+// 组件标签选择器，语法与Kubernetes的标签选择器一致，多个条件以逗号分隔，需同时满足：
+//
+//	role=middleware、role==middleware、role!=middleware
+//	env in (prod,staging)、env notin (dev)
+//	critical、!critical
+//
+// 空选择器匹配所有组件
+type Selector struct {
+	requirements []selectorRequirement
+}
+
+// 解析标签选择器
+func ParseSelector(s string) (selector Selector, err error) {
+	for _, part := range splitSelector(s) {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		var r selectorRequirement
+		if r, err = parseSelectorRequirement(part); err != nil {
+			err = fmt.Errorf("invalid selector %q: %w", s, err)
+			return
+		}
+		selector.requirements = append(selector.requirements, r)
+	}
+	return
+}
+
+// 解析标签选择器，失败时panic
+func MustParseSelector(s string) Selector {
+	selector, err := ParseSelector(s)
+	if err != nil {
+		panic(err)
+	}
+	return selector
+}
+
+// 判断标签是否满足选择器的所有条件
+func (s Selector) Matches(labels map[string]string) bool {
+	for _, r := range s.requirements {
+		if !r.matches(labels) {
+			return false
+		}
+	}
+	return true
+}
+
+func (s Selector) String() string {
+	parts := make([]string, 0, len(s.requirements))
+	for _, r := range s.requirements {
+		parts = append(parts, r.String())
+	}
+	return strings.Join(parts, ",")
+}
+
+// 按顶层的逗号切分选择器，括号内的逗号不切分
+func splitSelector(s string) (parts []string) {
+	depth, start := 0, 0
+	for i, ch := range s {
+		switch ch {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, s[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, s[start:])
+}
+
+func parseSelectorRequirement(part string) (r selectorRequirement, err error) {
+	if key, ok := strings.CutPrefix(part, "!"); ok {
+		r = selectorRequirement{key: strings.TrimSpace(key), op: selectorOpDoesNotExist}
+		return r, validateLabelKey(r.key)
+	}
+	for _, op := range []string{"!=", "==", "="} {
+		if key, value, ok := strings.Cut(part, op); ok {
+			r.key, r.values = strings.TrimSpace(key), []string{strings.TrimSpace(value)}
+			r.op = selectorOpEquals
+			if op == "!=" {
+				r.op = selectorOpNotEquals
+			}
+			return r, validateLabelKey(r.key)
+		}
+	}
+	fields := strings.Fields(part)
+	if len(fields) == 1 {
+		r = selectorRequirement{key: fields[0], op: selectorOpExists}
+		return r, validateLabelKey(r.key)
+	}
+	key, rest, _ := strings.Cut(part, " ")
+	rest = strings.TrimSpace(rest)
+	for _, op := range []selectorOperator{selectorOpNotIn, selectorOpIn} {
+		values, ok := strings.CutPrefix(rest, string(op))
+		if !ok {
+			continue
+		}
+		values = strings.TrimSpace(values)
+		if !strings.HasPrefix(values, "(") || !strings.HasSuffix(values, ")") {
+			err = fmt.Errorf("values of %s must be enclosed in parentheses", op)
+			return
+		}
+		r = selectorRequirement{key: key, op: op}
+		for _, v := range strings.Split(values[1:len(values)-1], ",") {
+			if v = strings.TrimSpace(v); v != "" {
+				r.values = append(r.values, v)
+			}
+		}
+		if len(r.values) == 0 {
+			err = fmt.Errorf("values of %s must not be empty", op)
+			return
+		}
+		return r, validateLabelKey(r.key)
+	}
+	err = fmt.Errorf("unknown requirement %q", part)
+	return
+}
+
+func validateLabelKey(key string) error {
+	if key == "" || strings.ContainsAny(key, " ()!=,") {
+		return fmt.Errorf("invalid label key %q", key)
+	}
+	return nil
+}
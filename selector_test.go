@@ -0,0 +1,97 @@
+package compcont
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelector(t *testing.T) {
+	labels := map[string]string{"role": "middleware", "env": "prod"}
+	for selector, expected := range map[string]bool{
+		"":                              true,
+		"role=middleware":               true,
+		"role==middleware,env!=dev":     true,
+		"env in (prod, staging)":        true,
+		"env notin (prod)":              false,
+		"role,!critical":                true,
+		"critical":                      false,
+		"role=middleware,env in (dev)":  false,
+		"role in (middleware),tier!=db": true,
+	} {
+		s, err := ParseSelector(selector)
+		assert.NoError(t, err, selector)
+		assert.Equal(t, expected, s.Matches(labels), selector)
+	}
+
+	for _, selector := range []string{"env in prod", "env in ()", "=x", "a b c"} {
+		_, err := ParseSelector(selector)
+		assert.Error(t, err, selector)
+	}
+}
+
+type middlewareChainConfig struct {
+	Middlewares ComponentGroup[IComponentA] `ccf:"middlewares"`
+}
+
+func TestComponentGroup(t *testing.T) {
+	registry := NewFactoryRegistry()
+	MustRegister(registry, factoryA)
+	MustRegister(registry, &TypedSimpleComponentFactory[middlewareChainConfig, []string]{
+		TypeID: "chain",
+		CreateInstanceFunc: func(ctx BuildContext, config middlewareChainConfig) (instance []string, err error) {
+			for _, m := range config.Middlewares.MustLoadComponents(ctx.Container) {
+				instance = append(instance, m.Instance.GetConfigA().TestA)
+			}
+			return
+		},
+	})
+	container := NewComponentContainer(WithFactoryRegistry(registry))
+	err := container.LoadNamedComponents([]ComponentConfig{
+		{Name: "auth", Type: "a", Labels: map[string]string{"role": "middleware"}, Config: ConfigA{TestA: "auth"}},
+		{Name: "gzip", Type: "a", Labels: map[string]string{"role": "middleware"}, Deps: []ComponentName{"auth"}, Config: ConfigA{TestA: "gzip"}},
+		{Name: "other", Type: "a"},
+		{Name: "chain", Type: "chain", Deps: []ComponentName{"auth", "gzip"}, Config: map[string]any{
+			"middlewares": map[string]any{"selector": "role=middleware"},
+		}},
+	})
+	assert.NoError(t, err)
+
+	chain, err := GetComponent[[]string](container, "chain")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"auth", "gzip"}, chain.Instance)
+}
+
+func TestComponentGroupImplicitDeps(t *testing.T) {
+	registry := NewFactoryRegistry()
+	MustRegister(registry, factoryA)
+	MustRegister(registry, &TypedSimpleComponentFactory[middlewareChainConfig, []string]{
+		TypeID: "chain",
+		CreateInstanceFunc: func(ctx BuildContext, config middlewareChainConfig) (instance []string, err error) {
+			for _, m := range config.Middlewares.MustLoadComponents(ctx.Container) {
+				instance = append(instance, m.Instance.GetConfigA().TestA)
+			}
+			return
+		},
+	})
+	container := NewComponentContainer(WithFactoryRegistry(registry))
+	assert.NoError(t, container.LoadNamedComponents([]ComponentConfig{
+		{Name: "auth", Type: "a", Labels: map[string]string{"role": "middleware"}, Config: ConfigA{TestA: "auth"}},
+	}))
+
+	// 组成员无需列在Deps中，同一批加载的成员先于组件构建
+	err := container.LoadNamedComponents([]ComponentConfig{
+		{Name: "chain", Type: "chain", Config: map[string]any{
+			"middlewares": map[string]any{"selector": "role=middleware"},
+		}},
+		{Name: "gzip", Type: "a", Labels: map[string]string{"role": "middleware"}, Deps: []ComponentName{"auth"}, Lazy: true, Config: ConfigA{TestA: "gzip"}},
+	})
+	assert.NoError(t, err)
+	chain, err := GetComponent[[]string](container, "chain")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"auth", "gzip"}, chain.Instance)
+	assert.Equal(t, []ComponentName{"auth?", "gzip?"}, chain.BuildContext.Config.Deps)
+
+	// 组成员被组件依赖，不能单独卸载
+	assert.ErrorIs(t, container.UnloadNamedComponents([]ComponentName{"gzip"}, false), ErrComponentHasDependents)
+}
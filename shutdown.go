@@ -0,0 +1,158 @@
+package compcont
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"slices"
+	"syscall"
+	"time"
+)
+
+// 需要在停止前先停止接收新任务的组件实例可实现该接口，如关闭监听端口、暂停消费消息。
+// ComponentContainer自身实现了该接口，子容器随父容器一同排空
+type IDrainable interface {
+	Drain(ctx context.Context) error
+}
+
+// Drain 按构建的逆序排空容器中归属于当前容器的已创建组件，单个组件失败不影响其余组件
+func (c *ComponentContainer) Drain(ctx context.Context) error {
+	var errs []error
+	for _, name := range slices.Backward(c.LoadedComponentNames()) {
+		component, ok := c.lifecycleComponent(name)
+		if !ok {
+			continue
+		}
+		drainable, ok := component.Instance.(IDrainable)
+		if !ok {
+			continue
+		}
+		start := time.Now()
+		err := drainable.Drain(ctx)
+		event := Event{Type: EventDrained, Time: time.Now(), Context: component.BuildContext, Duration: time.Since(start), Err: err}
+		if err != nil {
+			event.Type = EventDrainFailed
+			errs = append(errs, fmt.Errorf("drain component %s: %w", name, err))
+		}
+		c.emit(&event)
+	}
+	return errors.Join(errs...)
+}
+
+// 进程退出码
+const (
+	ExitOK             = 0 // 正常退出
+	ExitShutdownFailed = 1 // 启动失败、停机过程出错或超过停机期限
+	ExitForced         = 2 // 停机过程中再次收到信号，强制退出
+)
+
+// 信号驱动的停机选项
+type ShutdownOptions struct {
+	Signals   []os.Signal        // 触发停机的信号，不填为SIGINT与SIGTERM
+	Timeout   time.Duration      // 停机的硬性期限，超过后不再等待，不填为30秒
+	Exit      func(code int)     // 再次收到信号时的强制退出函数，不填为os.Exit
+	OnStarted func()             // 容器启动成功后的回调
+	OnStage   func(stage string) // 停机每个阶段开始时的回调，阶段依次为drain、stop、destroy
+}
+
+// RunUntilSignal 启动容器并运行到收到信号或ctx被取消，随后分阶段停机：排空组件、按逆序停止组件、销毁组件。
+// 停机超过期限时直接返回，停机过程中再次收到信号时调用Exit强制退出。返回值为进程应使用的退出码，通常的用法为
+//
+//	os.Exit(compcont.RunUntilSignal(ctx, container, compcont.ShutdownOptions{}))
+func RunUntilSignal(ctx context.Context, container IComponentContainer, opt ShutdownOptions) int {
+	if len(opt.Signals) == 0 {
+		opt.Signals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+	}
+	if opt.Timeout <= 0 {
+		opt.Timeout = 30 * time.Second
+	}
+	if opt.Exit == nil {
+		opt.Exit = os.Exit
+	}
+	logger := discardLogger
+	if c, ok := container.(*ComponentContainer); ok {
+		logger = c.log()
+	}
+
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, opt.Signals...)
+	defer signal.Stop(signals)
+
+	if err := startContainer(ctx, container); err != nil {
+		logger.Error("start container failed", "error", err)
+		if err = unloadAll(container); err != nil {
+			logger.Error("destroy components failed", "error", err)
+		}
+		return ExitShutdownFailed
+	}
+	if opt.OnStarted != nil {
+		opt.OnStarted()
+	}
+
+	select {
+	case sig := <-signals:
+		logger.Info("shutting down", "signal", sig.String())
+	case <-ctx.Done():
+		logger.Info("shutting down", "reason", ctx.Err())
+	}
+
+	// 停机不受已取消的ctx影响，只受期限约束
+	shutdownCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), opt.Timeout)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		done <- shutdown(shutdownCtx, container, opt.OnStage)
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			logger.Error("shutdown failed", "error", err)
+			return ExitShutdownFailed
+		}
+		return ExitOK
+	case <-shutdownCtx.Done():
+		logger.Error("shutdown deadline exceeded", "timeout", opt.Timeout)
+		return ExitShutdownFailed
+	case sig := <-signals:
+		logger.Error("forced exit", "signal", sig.String())
+		opt.Exit(ExitForced)
+		return ExitForced
+	}
+}
+
+// 分阶段停机
+func shutdown(ctx context.Context, container IComponentContainer, onStage func(stage string)) error {
+	stage := func(name string) {
+		if onStage != nil {
+			onStage(name)
+		}
+	}
+	var errs []error
+	if drainable, ok := container.(IDrainable); ok {
+		stage("drain")
+		errs = append(errs, drainable.Drain(ctx))
+	}
+	stage("stop")
+	if stoppable, ok := container.(IStoppable); ok {
+		errs = append(errs, stoppable.Stop(ctx))
+	}
+	stage("destroy")
+	errs = append(errs, unloadAll(container))
+	return errors.Join(errs...)
+}
+
+// 启动容器，容器未实现IStartable时不做处理
+func startContainer(ctx context.Context, container IComponentContainer) error {
+	if startable, ok := container.(IStartable); ok {
+		return startable.Start(ctx)
+	}
+	return nil
+}
+
+// 按依赖关系的逆序卸载容器中的所有组件
+func unloadAll(container IComponentContainer) error {
+	return container.UnloadNamedComponents(container.LoadedComponentNames(), true)
+}
@@ -0,0 +1,112 @@
+//go:build unix
+
+package compcont
+
+import (
+	"context"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type server struct {
+	mu      sync.Mutex
+	log     []string
+	started chan struct{}
+	block   chan struct{} // 不为nil时Stop阻塞到其被关闭
+}
+
+func (s *server) record(msg string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.log = append(s.log, msg)
+}
+
+func (s *server) Start(ctx context.Context) error {
+	close(s.started)
+	return nil
+}
+
+func (s *server) Drain(ctx context.Context) error {
+	s.record("drain")
+	return nil
+}
+
+func (s *server) Stop(ctx context.Context) error {
+	s.record("stop")
+	if s.block != nil {
+		<-s.block
+	}
+	return nil
+}
+
+func runServer(t *testing.T, srv *server, opt ShutdownOptions) (code chan int) {
+	registry := NewFactoryRegistry()
+	MustRegister(registry, &TypedSimpleComponentFactory[struct{}, *server]{
+		TypeID: "server",
+		CreateInstanceFunc: func(ctx BuildContext, config struct{}) (instance *server, err error) {
+			return srv, nil
+		},
+		DestroyInstanceFunc: func(ctx BuildContext, instance *server) (err error) {
+			instance.record("destroy")
+			return
+		},
+	})
+	container := NewComponentContainer(WithFactoryRegistry(registry))
+	assert.NoError(t, container.LoadNamedComponents([]ComponentConfig{{Name: "server", Type: "server"}}))
+
+	code = make(chan int, 1)
+	go func() { code <- RunUntilSignal(context.Background(), container, opt) }()
+	<-srv.started
+	return
+}
+
+func waitCode(t *testing.T, code chan int) int {
+	select {
+	case c := <-code:
+		return c
+	case <-time.After(time.Second):
+		t.Fatal("shutdown not finished")
+		return -1
+	}
+}
+
+func TestRunUntilSignal(t *testing.T) {
+	srv := &server{started: make(chan struct{})}
+	code := runServer(t, srv, ShutdownOptions{})
+	assert.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGTERM))
+	assert.Equal(t, ExitOK, waitCode(t, code))
+	assert.Equal(t, []string{"drain", "stop", "destroy"}, srv.log)
+}
+
+func TestRunUntilSignalForced(t *testing.T) {
+	srv := &server{started: make(chan struct{}), block: make(chan struct{})}
+	defer close(srv.block)
+	stopping := make(chan struct{})
+	exited := make(chan int, 1)
+	code := runServer(t, srv, ShutdownOptions{
+		Exit: func(code int) { exited <- code },
+		OnStage: func(stage string) {
+			if stage == "stop" {
+				close(stopping)
+			}
+		},
+	})
+	assert.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGINT))
+	<-stopping
+	// 停机过程中再次收到信号时强制退出
+	assert.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGINT))
+	assert.Equal(t, ExitForced, waitCode(t, code))
+	assert.Equal(t, ExitForced, <-exited)
+}
+
+func TestRunUntilSignalDeadline(t *testing.T) {
+	srv := &server{started: make(chan struct{}), block: make(chan struct{})}
+	defer close(srv.block)
+	code := runServer(t, srv, ShutdownOptions{Timeout: 20 * time.Millisecond})
+	assert.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGTERM))
+	assert.Equal(t, ExitShutdownFailed, waitCode(t, code))
+}
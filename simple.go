@@ -1,8 +1,10 @@
 package compcont
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
+	"strings"
 	"time"
 
 	"github.com/mitchellh/mapstructure"
@@ -30,9 +32,9 @@ func GetComponent[Instance any](container IComponentContainer, name ComponentNam
 	return
 }
 
-// 根据指定类型加载一个组件实例
-func LoadAnonymousComponent[Instance any](container IComponentContainer, config ComponentConfig) (ret TypedComponent[Instance], err error) {
-	r, err := container.LoadAnonymousComponent(config)
+// 根据指定类型加载一个组件实例，loader为BuildContext时加载的组件归属于正在构造的组件
+func LoadAnonymousComponent[Instance any](loader IComponentLoader, config ComponentConfig) (ret TypedComponent[Instance], err error) {
+	r, err := loader.LoadAnonymousComponent(config)
 	if err != nil {
 		return
 	}
@@ -85,13 +87,16 @@ func (f TypedCreateInstanceFunc[Config, Instance]) ToAny() CreateInstanceFunc {
 			return f(ctx, v)
 		case map[string]any:
 			var cfg Config
+			start := time.Now()
 			err = decodeMapConfig(v, &cfg)
+			ctx.recordPhase(PhaseDecode, start, time.Since(start))
 			if err != nil {
+				err = fmt.Errorf("%w, decode config: %w", ErrComponentConfigInvalid, err)
 				return
 			}
 			return f(ctx, cfg)
 		default:
-			err = fmt.Errorf("unexpected config type %s", reflect.ValueOf(rawConfig))
+			err = fmt.Errorf("%w, unexpected config type %s", ErrComponentConfigInvalid, reflect.ValueOf(rawConfig))
 			return
 		}
 	}
@@ -110,29 +115,43 @@ func (f TypedDestroyInstanceFunc[Component]) ToAny() DestroyInstanceFunc {
 }
 
 type TypedComponentConfig[Config any, Component any] struct {
-	Name   ComponentName   `json:"name" yaml:"name"`
-	Type   ComponentTypeID `json:"type" yaml:"type"`     // 组件类型
-	Refer  string          `json:"refer" yaml:"refer"`   // 来自其他组件的引用
-	Deps   []ComponentName `json:"deps" yaml:"deps"`     // 构造该组件需要依赖的其他组件名称
-	Config Config          `json:"config" yaml:"config"` // 组件的自身配置
+	Name    ComponentName     `json:"name" yaml:"name"`
+	Type    ComponentTypeID   `json:"type" yaml:"type"`       // 组件类型
+	Refer   string            `json:"refer" yaml:"refer"`     // 来自其他组件的引用
+	Deps    []ComponentName   `json:"deps" yaml:"deps"`       // 构造该组件需要依赖的其他组件名称
+	Config  Config            `json:"config" yaml:"config"`   // 组件的自身配置
+	Enabled *bool             `json:"enabled" yaml:"enabled"` // 是否启用，不填为启用
+	When    string            `json:"when" yaml:"when"`       // 启用条件表达式
+	Labels  map[string]string `json:"labels" yaml:"labels"`   // 组件标签
+	Scope   ComponentScope    `json:"scope" yaml:"scope"`     // 组件作用域
+	Lazy    bool              `json:"lazy" yaml:"lazy"`       // 是否懒加载
+	Health  HealthConfig      `json:"health" yaml:"health"`   // 健康检查配置
+	Retry   RetryConfig       `json:"retry" yaml:"retry"`     // 创建失败时的重试配置
 }
 
 func (c TypedComponentConfig[Config, Component]) ToAny() ComponentConfig {
 	return ComponentConfig{
-		Name:   c.Name,
-		Type:   c.Type,
-		Refer:  c.Refer,
-		Deps:   c.Deps,
-		Config: c.Config,
+		Name:    c.Name,
+		Type:    c.Type,
+		Refer:   c.Refer,
+		Deps:    c.Deps,
+		Config:  c.Config,
+		Enabled: c.Enabled,
+		When:    c.When,
+		Labels:  c.Labels,
+		Scope:   c.Scope,
+		Lazy:    c.Lazy,
+		Health:  c.Health,
+		Retry:   c.Retry,
 	}
 }
 
-func (c TypedComponentConfig[Config, Component]) LoadComponent(container IComponentContainer) (component TypedComponent[Component], err error) {
-	return LoadAnonymousComponent[Component](container, c.ToAny())
+func (c TypedComponentConfig[Config, Component]) LoadComponent(loader IComponentLoader) (component TypedComponent[Component], err error) {
+	return LoadAnonymousComponent[Component](loader, c.ToAny())
 }
 
-func (c TypedComponentConfig[Config, Component]) MustLoadComponent(container IComponentContainer) (component TypedComponent[Component]) {
-	component, err := LoadAnonymousComponent[Component](container, c.ToAny())
+func (c TypedComponentConfig[Config, Component]) MustLoadComponent(loader IComponentLoader) (component TypedComponent[Component]) {
+	component, err := LoadAnonymousComponent[Component](loader, c.ToAny())
 	if err != nil {
 		panic(fmt.Errorf("load component failed: %w", err))
 	}
@@ -141,6 +160,7 @@ func (c TypedComponentConfig[Config, Component]) MustLoadComponent(container ICo
 
 type TypedSimpleComponentFactory[Config any, Component any] struct {
 	TypeID              ComponentTypeID
+	Description         string // 组件的说明，用于命令行工具展示
 	CreateInstanceFunc  TypedCreateInstanceFunc[Config, Component]
 	DestroyInstanceFunc TypedDestroyInstanceFunc[Component]
 }
@@ -162,3 +182,198 @@ func (s *TypedSimpleComponentFactory[Config, Component]) DestroyInstance(ctx Bui
 	}
 	return s.DestroyInstanceFunc.ToAny()(ctx, instance)
 }
+
+// 实现IInstanceTyped
+func (s *TypedSimpleComponentFactory[Config, Component]) InstanceType() reflect.Type {
+	return reflect.TypeFor[Component]()
+}
+
+// 实现iGroupSelectorsProvider，按创建实例时的规则解码配置后查找组件组
+func (s *TypedSimpleComponentFactory[Config, Component]) groupSelectors(config any) []string {
+	var cfg Config
+	switch v := config.(type) {
+	case Config:
+		cfg = v
+	case map[string]any:
+		if decodeMapConfig(v, &cfg) != nil {
+			return nil
+		}
+	default:
+		return nil
+	}
+	return findGroupSelectors(reflect.ValueOf(cfg), make(set[uintptr]))
+}
+
+// 实现IFactoryDescriber
+func (s *TypedSimpleComponentFactory[Config, Component]) Describe() FactoryInfo {
+	return FactoryInfo{
+		Type:         s.TypeID,
+		Description:  s.Description,
+		ConfigType:   reflect.TypeFor[Config]().String(),
+		InstanceType: reflect.TypeFor[Component]().String(),
+		ConfigSchema: ConfigSchema(reflect.TypeFor[Config]()),
+	}
+}
+
+// 实现IConfigValidator，按创建实例时的规则解码配置
+func (s *TypedSimpleComponentFactory[Config, Component]) ValidateConfig(config any) (err error) {
+	switch v := config.(type) {
+	case nil, Config:
+	case map[string]any:
+		var cfg Config
+		err = decodeMapConfig(v, &cfg)
+	default:
+		err = fmt.Errorf("unexpected config type %s", reflect.ValueOf(config))
+	}
+	return
+}
+
+// 查找容器中实例实现了Instance类型的唯一组件，searchAncestors为true时，当前容器中找不到则逐级向父容器查找
+func ResolveComponent[Instance any](container IComponentContainer, searchAncestors bool) (ret TypedComponent[Instance], err error) {
+	for current := container; current != nil; current = current.GetParent() {
+		var names []ComponentName
+		names, err = matchComponents(current, reflect.TypeFor[Instance](), true)
+		if err != nil {
+			return
+		}
+		if len(names) == 1 {
+			return GetComponent[Instance](current, names[0])
+		}
+		if len(names) > 1 {
+			candidates := make([]string, 0, len(names))
+			for _, name := range names {
+				candidates = append(candidates, name.String())
+			}
+			err = fmt.Errorf("%w, instance type: %v, candidates: %s", ErrComponentAmbiguous, reflect.TypeFor[Instance](), strings.Join(candidates, ", "))
+			return
+		}
+		if !searchAncestors {
+			break
+		}
+	}
+	err = fmt.Errorf("%w, no component implements %v", ErrComponentNameNotFound, reflect.TypeFor[Instance]())
+	return
+}
+
+// 查找容器中实例实现了Instance类型的所有组件，按构建顺序返回。
+// 匹配规则与ResolveComponent一致，只会创建匹配的懒加载组件，瞬态组件没有固定的实例，不会返回
+func ResolveComponents[Instance any](container IComponentContainer) (ret []TypedComponent[Instance], err error) {
+	names, err := matchComponents(container, reflect.TypeFor[Instance](), false)
+	if err != nil {
+		return
+	}
+	for _, name := range names {
+		var r TypedComponent[Instance]
+		if r, err = GetComponent[Instance](container, name); err != nil {
+			return
+		}
+		ret = append(ret, r)
+	}
+	return
+}
+
+// 在不创建实例的情况下按构建顺序查找实例类型可赋值给target的组件，includeTransient为false时忽略瞬态组件
+func matchComponents(container IComponentContainer, target reflect.Type, includeTransient bool) (names []ComponentName, err error) {
+	for _, name := range container.LoadedComponentNames() {
+		var info ComponentInfo
+		info, err = InspectComponent(container, name)
+		if errors.Is(err, ErrComponentScopeRequired) { // 未实现IComponentInspector的容器中，scoped组件只能在作用域内获取
+			err = nil
+			continue
+		}
+		if err != nil {
+			return
+		}
+		if info.Config.Scope == ScopeTransient && !includeTransient {
+			continue
+		}
+		if t := componentType(container.FactoryRegistry(), info); t != nil && t.AssignableTo(target) {
+			names = append(names, name)
+		}
+	}
+	return
+}
+
+// 确定组件实例的类型：优先取构造函数的返回值类型或工厂通过IInstanceTyped声明的类型，匹配结果与实例是否已创建无关。
+// 未声明类型的工厂只按非懒加载组件已创建实例的实际类型匹配。scoped组件只能在作用域内获取，无法确定类型时返回nil
+func componentType(registry IFactoryRegistry, info ComponentInfo) reflect.Type {
+	if info.Config.Scope == ScopeScoped && info.Component.Instance == nil {
+		return nil
+	}
+	if t := declaredType(registry, info.Config); t != nil {
+		return t
+	}
+	if info.Config.Lazy { // 懒加载组件在创建前后的类型需保持一致
+		return nil
+	}
+	return reflect.TypeOf(info.Component.Instance)
+}
+
+// 构造函数的返回值类型或工厂声明的实例类型
+func declaredType(registry IFactoryRegistry, config ComponentConfig) reflect.Type {
+	if config.Type == "" {
+		return nil
+	}
+	if spec, ok := config.Config.(*providerSpec); ok { // 构造函数组件的实例类型为其返回值类型
+		return spec.fn.Type().Out(0)
+	}
+	factory, err := registry.GetFactory(config.Type)
+	if err != nil {
+		return nil
+	}
+	if typed, ok := factory.(IInstanceTyped); ok {
+		return typed.InstanceType()
+	}
+	return nil
+}
+
+// 按构建顺序获取标签满足选择器的所有组件，组件实例必须实现Instance类型
+func ListComponents[Instance any](container IComponentContainer, selector string) (ret []TypedComponent[Instance], err error) {
+	s, err := ParseSelector(selector)
+	if err != nil {
+		return
+	}
+	for _, r := range listComponents(container, s) {
+		instance, ok := r.Instance.(Instance)
+		if !ok {
+			err = fmt.Errorf("list components failed, %w, name: %s, component type: %s, expected instance type %v, but got %v", ErrComponentTypeMismatch, r.BuildContext.Config.Name, r.BuildContext.Config.Type, reflect.TypeFor[Instance](), reflect.TypeOf(r.Instance))
+			return
+		}
+		ret = append(ret, TypedComponent[Instance]{
+			BuildContext: r.BuildContext,
+			Instance:     instance,
+		})
+	}
+	return
+}
+
+// 容器未实现IComponentLister时，逐个获取已加载的组件并按其配置中的标签匹配
+func listComponents(container IComponentContainer, selector Selector) (components []Component) {
+	if lister, ok := container.(IComponentLister); ok {
+		return lister.ListComponents(selector)
+	}
+	for _, name := range container.LoadedComponentNames() {
+		if r, err := container.GetComponent(name); err == nil && selector.Matches(r.BuildContext.Config.Labels) {
+			components = append(components, r)
+		}
+	}
+	return
+}
+
+// 一组组件的配置，作为组件配置的字段时，可将标签满足选择器的一组组件注入到组件中。
+// 同一批加载或已存在的组成员会作为组件的可选依赖，先于组件构建，组件无需在Deps中列出组成员
+type ComponentGroup[Instance any] struct {
+	Selector string `json:"selector" yaml:"selector"` // 标签选择器
+}
+
+func (g ComponentGroup[Instance]) LoadComponents(container IComponentContainer) (components []TypedComponent[Instance], err error) {
+	return ListComponents[Instance](container, g.Selector)
+}
+
+func (g ComponentGroup[Instance]) MustLoadComponents(container IComponentContainer) (components []TypedComponent[Instance]) {
+	components, err := g.LoadComponents(container)
+	if err != nil {
+		panic(fmt.Errorf("load component group failed: %w", err))
+	}
+	return
+}
@@ -0,0 +1,120 @@
+package compcont
+
+import (
+	"context"
+	"sync"
+)
+
+// 组件配置的来源
+type IConfigSource interface {
+	Load(ctx context.Context) (configs []ComponentConfig, err error) // 读取当前的组件配置
+	Watch(ctx context.Context) <-chan struct{}                       // 配置可能发生变化时发出通知，ctx结束后停止；不支持变化通知时返回nil
+}
+
+// 向通知channel发送一次通知，未被消费的通知会被合并
+func notify(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
+
+// 内存中的配置来源，通过Set更新配置
+type MemoryConfigSource struct {
+	mu       sync.RWMutex
+	configs  []ComponentConfig
+	watchers []chan struct{}
+}
+
+func NewMemoryConfigSource(configs ...ComponentConfig) *MemoryConfigSource {
+	return &MemoryConfigSource{configs: configs}
+}
+
+// Load implements IConfigSource.
+func (s *MemoryConfigSource) Load(ctx context.Context) (configs []ComponentConfig, err error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append(configs, s.configs...), nil
+}
+
+// Watch implements IConfigSource.
+func (s *MemoryConfigSource) Watch(ctx context.Context) <-chan struct{} {
+	ch := make(chan struct{}, 1)
+	s.mu.Lock()
+	s.watchers = append(s.watchers, ch)
+	s.mu.Unlock()
+	go func() {
+		<-ctx.Done()
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		for i, w := range s.watchers {
+			if w == ch {
+				s.watchers = append(s.watchers[:i], s.watchers[i+1:]...)
+				break
+			}
+		}
+	}()
+	return ch
+}
+
+// 替换全部配置并通知监听方
+func (s *MemoryConfigSource) Set(configs []ComponentConfig) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.configs = configs
+	for _, ch := range s.watchers {
+		notify(ch)
+	}
+}
+
+// 组合多个配置来源，优先级按顺序递增，同名组件以优先级高的来源为准
+type CompositeConfigSource struct {
+	sources []IConfigSource
+}
+
+func NewCompositeConfigSource(sources ...IConfigSource) *CompositeConfigSource {
+	return &CompositeConfigSource{sources: sources}
+}
+
+// Load implements IConfigSource.
+func (s *CompositeConfigSource) Load(ctx context.Context) (configs []ComponentConfig, err error) {
+	index := make(map[ComponentName]int)
+	for _, source := range s.sources {
+		var sourceConfigs []ComponentConfig
+		sourceConfigs, err = source.Load(ctx)
+		if err != nil {
+			return
+		}
+		for _, cfg := range sourceConfigs {
+			if i, ok := index[cfg.Name]; ok && cfg.Name != "" {
+				configs[i] = cfg
+				continue
+			}
+			index[cfg.Name] = len(configs)
+			configs = append(configs, cfg)
+		}
+	}
+	return
+}
+
+// Watch implements IConfigSource.
+func (s *CompositeConfigSource) Watch(ctx context.Context) <-chan struct{} {
+	out := make(chan struct{}, 1)
+	for _, source := range s.sources {
+		ch := source.Watch(ctx)
+		if ch == nil {
+			continue
+		}
+		go func() {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-ch:
+					notify(out)
+				}
+			}
+		}()
+	}
+	return out
+}
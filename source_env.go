@@ -0,0 +1,114 @@
+package compcont
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// 基于环境变量的配置来源，变量名约定如下（以前缀APP为例）：
+//
+//	APP_<NAME>__TYPE=redis
+//	APP_<NAME>__REFER=/infra/redis
+//	APP_<NAME>__DEPS=a,b
+//	APP_<NAME>__CONFIG__<KEY>[__<SUBKEY>...]=value
+//
+// 组件名与配置键均转为小写，配置值按YAML标量解析，如10解析为整数、true解析为布尔值
+type EnvConfigSource struct {
+	Prefix  string
+	Environ func() []string // 默认为os.Environ
+}
+
+func NewEnvConfigSource(prefix string) *EnvConfigSource {
+	return &EnvConfigSource{Prefix: prefix, Environ: os.Environ}
+}
+
+// Load implements IConfigSource.
+func (s *EnvConfigSource) Load(ctx context.Context) (configs []ComponentConfig, err error) {
+	environ := s.Environ
+	if environ == nil {
+		environ = os.Environ
+	}
+	prefix := s.Prefix + "_"
+	configMap := make(map[ComponentName]*ComponentConfig)
+	for _, kv := range environ() {
+		key, value, _ := strings.Cut(kv, "=")
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+		parts := strings.Split(strings.TrimPrefix(key, prefix), "__")
+		if len(parts) < 2 {
+			continue
+		}
+		name := ComponentName(strings.ToLower(parts[0]))
+		cfg, ok := configMap[name]
+		if !ok {
+			cfg = &ComponentConfig{Name: name}
+			configMap[name] = cfg
+		}
+		switch field := strings.ToUpper(parts[1]); {
+		case field == "TYPE" && len(parts) == 2:
+			cfg.Type = ComponentTypeID(value)
+		case field == "REFER" && len(parts) == 2:
+			cfg.Refer = value
+		case field == "DEPS" && len(parts) == 2:
+			for _, dep := range strings.Split(value, ",") {
+				if dep = strings.TrimSpace(dep); dep != "" {
+					cfg.Deps = append(cfg.Deps, ComponentName(dep))
+				}
+			}
+		case field == "CONFIG" && len(parts) > 2:
+			if err = setEnvConfigValue(cfg, parts[2:], value); err != nil {
+				err = fmt.Errorf("%w, env %s: %w", ErrComponentConfigInvalid, key, err)
+				return
+			}
+		default:
+			err = fmt.Errorf("%w, unknown env %s", ErrComponentConfigInvalid, key)
+			return
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(configMap)) {
+		configs = append(configs, *configMap[name])
+	}
+	return
+}
+
+// Watch implements IConfigSource. 环境变量在进程内不会变化
+func (s *EnvConfigSource) Watch(ctx context.Context) <-chan struct{} {
+	return nil
+}
+
+// 将环境变量的值写入组件配置的嵌套map中
+func setEnvConfigValue(cfg *ComponentConfig, keys []string, value string) (err error) {
+	if cfg.Config == nil {
+		cfg.Config = map[string]any{}
+	}
+	current := cfg.Config.(map[string]any)
+	for i, key := range keys {
+		key = strings.ToLower(key)
+		if i == len(keys)-1 {
+			var v any
+			if yaml.Unmarshal([]byte(value), &v) != nil || v == nil {
+				v = value // 无法解析的值按字符串处理
+			}
+			current[key] = v
+			return
+		}
+		next, ok := current[key].(map[string]any)
+		if !ok {
+			if _, exists := current[key]; exists {
+				return fmt.Errorf("config key %s conflicts", key)
+			}
+			next = map[string]any{}
+			current[key] = next
+		}
+		current = next
+	}
+	return
+}
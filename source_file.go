@@ -0,0 +1,75 @@
+package compcont
+
+import (
+	"context"
+	"crypto/sha256"
+	"os"
+	"time"
+)
+
+// 基于本地文件与目录的配置来源，通过轮询文件内容感知变化
+type FileConfigSource struct {
+	Paths    []string      // 配置文件或目录
+	Interval time.Duration // 轮询间隔，默认1秒
+}
+
+func NewFileConfigSource(paths ...string) *FileConfigSource {
+	return &FileConfigSource{Paths: paths, Interval: time.Second}
+}
+
+// Load implements IConfigSource.
+func (s *FileConfigSource) Load(ctx context.Context) (configs []ComponentConfig, err error) {
+	return LoadConfigPaths(s.Paths...)
+}
+
+// Watch implements IConfigSource.
+func (s *FileConfigSource) Watch(ctx context.Context) <-chan struct{} {
+	ch := make(chan struct{}, 1)
+	interval := s.Interval
+	if interval <= 0 {
+		interval = time.Second
+	}
+	last, _ := s.fingerprint()
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			fp, err := s.fingerprint()
+			if err != nil { // 原子替换的过程中文件可能短暂不存在，等待下一次轮询
+				continue
+			}
+			if fp != last {
+				last = fp
+				notify(ch)
+			}
+		}
+	}()
+	return ch
+}
+
+// 计算所有配置文件内容的摘要，内容不变则摘要不变
+func (s *FileConfigSource) fingerprint() (fp [sha256.Size]byte, err error) {
+	files, err := resolveConfigFiles(s.Paths)
+	if err != nil {
+		return
+	}
+	h := sha256.New()
+	for _, file := range files {
+		var data []byte
+		data, err = os.ReadFile(file)
+		if err != nil {
+			return
+		}
+		h.Write([]byte(file))
+		h.Write([]byte{0})
+		h.Write(data)
+		h.Write([]byte{0})
+	}
+	copy(fp[:], h.Sum(nil))
+	return
+}
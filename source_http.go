@@ -0,0 +1,127 @@
+package compcont
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 基于HTTP接口的配置来源，使用ETag进行条件请求，轮询感知配置变化
+type HTTPConfigSource struct {
+	URL      string
+	Client   *http.Client  // 默认为http.DefaultClient
+	Interval time.Duration // 轮询间隔，默认10秒
+
+	mu      sync.Mutex
+	etag    string
+	body    []byte
+	configs []ComponentConfig
+}
+
+func NewHTTPConfigSource(url string) *HTTPConfigSource {
+	return &HTTPConfigSource{URL: url, Interval: 10 * time.Second}
+}
+
+// Load implements IConfigSource.
+func (s *HTTPConfigSource) Load(ctx context.Context) (configs []ComponentConfig, err error) {
+	_, err = s.fetch(ctx)
+	if err != nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append(configs, s.configs...), nil
+}
+
+// Watch implements IConfigSource.
+func (s *HTTPConfigSource) Watch(ctx context.Context) <-chan struct{} {
+	ch := make(chan struct{}, 1)
+	interval := s.Interval
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			// 请求失败时等待下一次轮询，由Load向调用方报告错误
+			if changed, err := s.fetch(ctx); err == nil && changed {
+				notify(ch)
+			}
+		}
+	}()
+	return ch
+}
+
+// 拉取远端配置，返回配置是否发生了变化
+// 请求期间不持有锁，避免慢速的远端阻塞Load
+func (s *HTTPConfigSource) fetch(ctx context.Context) (changed bool, err error) {
+	s.mu.Lock()
+	etag := s.etag
+	s.mu.Unlock()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.URL, nil)
+	if err != nil {
+		return
+	}
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		return
+	case http.StatusOK:
+	default:
+		err = fmt.Errorf("fetch config from %s: unexpected status %s", s.URL, resp.Status)
+		return
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return
+	}
+	s.mu.Lock()
+	unchanged := s.body != nil && bytes.Equal(data, s.body) // 服务端未提供ETag时按内容判断
+	if unchanged {
+		s.etag = resp.Header.Get("ETag") // 内容未变但ETag可能已更新
+	}
+	s.mu.Unlock()
+	if unchanged {
+		return
+	}
+	format := "json"
+	if strings.Contains(resp.Header.Get("Content-Type"), "yaml") {
+		format = "yaml"
+	}
+	configs, err := ParseConfigs(data, format)
+	if err != nil {
+		err = fmt.Errorf("fetch config from %s: %w", s.URL, err)
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.etag = resp.Header.Get("ETag")
+	s.body = data
+	s.configs = configs
+	return true, nil
+}
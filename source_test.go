@@ -0,0 +1,110 @@
+package compcont
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTTPConfigSource(t *testing.T) {
+	var version atomic.Int32
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		etag := `"v` + string(rune('0'+version.Load())) + `"`
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Content-Type", "application/yaml")
+		w.Write([]byte("- name: a\n  type: t" + string(rune('0'+version.Load())) + "\n"))
+	}))
+	defer server.Close()
+
+	source := NewHTTPConfigSource(server.URL)
+	source.Interval = 10 * time.Millisecond
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	configs, err := source.Load(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, ComponentTypeID("t0"), configs[0].Type)
+
+	changes := source.Watch(ctx)
+	version.Store(1)
+	<-changes
+	configs, err = source.Load(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, ComponentTypeID("t1"), configs[0].Type)
+	assert.Greater(t, requests.Load(), int32(2))
+}
+
+func TestHTTPConfigSourceETagRefresh(t *testing.T) {
+	var etag atomic.Value
+	etag.Store(`"a"`)
+	var conditional atomic.Value
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conditional.Store(r.Header.Get("If-None-Match"))
+		w.Header().Set("ETag", etag.Load().(string))
+		w.Write([]byte(`[{"name":"a","type":"t"}]`))
+	}))
+	defer server.Close()
+
+	source := NewHTTPConfigSource(server.URL)
+	ctx := context.Background()
+	changed, err := source.fetch(ctx)
+	assert.NoError(t, err)
+	assert.True(t, changed)
+
+	// 内容不变但ETag变化，后续请求应携带新的ETag
+	etag.Store(`"b"`)
+	changed, err = source.fetch(ctx)
+	assert.NoError(t, err)
+	assert.False(t, changed)
+	_, err = source.fetch(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, `"b"`, conditional.Load())
+}
+
+func TestCompositeConfigSource(t *testing.T) {
+	env := NewEnvConfigSource("APP")
+	env.Environ = func() []string {
+		return []string{
+			"APP_CACHE__TYPE=redis",
+			"APP_CACHE__DEPS=db",
+			"APP_CACHE__CONFIG__POOL__SIZE=10",
+			"APP_CACHE__CONFIG__ADDR=localhost:6379",
+			"OTHER_X__TYPE=ignored",
+		}
+	}
+	memory := NewMemoryConfigSource(
+		ComponentConfig{Name: "db", Type: "mysql"},
+		ComponentConfig{Name: "cache", Type: "memcache"},
+	)
+	source := NewCompositeConfigSource(memory, env)
+
+	configs, err := source.Load(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []ComponentConfig{
+		{Name: "db", Type: "mysql"},
+		{Name: "cache", Type: "redis", Deps: []ComponentName{"db"}, Config: map[string]any{
+			"pool": map[string]any{"size": 10},
+			"addr": "localhost:6379",
+		}},
+	}, configs)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes := source.Watch(ctx)
+	memory.Set([]ComponentConfig{{Name: "db", Type: "pg"}})
+	<-changes
+	configs, err = source.Load(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, ComponentTypeID("pg"), configs[0].Type)
+}
@@ -0,0 +1,206 @@
+package compcont
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+// 监督策略，决定组件报告致命错误后需要重建哪些组件
+type SupervisorStrategy string
+
+const (
+	OneForOne  SupervisorStrategy = "one_for_one"  // 重建出错的组件及其传递依赖方
+	RestForOne SupervisorStrategy = "rest_for_one" // 重建出错的组件以及构建顺序在其之后的所有组件
+)
+
+// 监督者的选项
+type SupervisorOptions struct {
+	Strategy    SupervisorStrategy // 不填为OneForOne
+	MaxRestarts int                // Period内允许的最大重建次数，超过后放弃重建并上报给父容器，不填为3
+	Period      time.Duration      // 统计重建次数的时间窗口，不填为5秒
+	Backoff     time.Duration      // 首次重建前的等待时间，之后每次翻倍，不填为100毫秒
+	MaxBackoff  time.Duration      // 重建前等待时间的上限，不填为10秒
+}
+
+// 设置容器的监督者，组件通过BuildContext.ReportFatal报告致命错误后由容器重建。
+// 未设置监督者的容器会将致命错误上报给父容器，由父容器重建整个子容器组件。只有已启动的容器会重建组件，容器停止时等待中的重建被取消
+func WithSupervisor(opt SupervisorOptions) Option {
+	return func(o *options) {
+		o.supervisor = &opt
+	}
+}
+
+// 容器的监督者
+type supervisor struct {
+	opt      SupervisorOptions
+	mu       sync.Mutex
+	pending  set[ComponentName] // 等待重建的组件
+	restarts []time.Time        // 时间窗口内的重建时间
+	ctx      context.Context    // 容器启动时创建，停止时取消，未启动时为nil
+	cancel   context.CancelFunc
+}
+
+func newSupervisor(opt SupervisorOptions) *supervisor {
+	if opt.Strategy == "" {
+		opt.Strategy = OneForOne
+	}
+	if opt.MaxRestarts <= 0 {
+		opt.MaxRestarts = 3
+	}
+	if opt.Period <= 0 {
+		opt.Period = 5 * time.Second
+	}
+	if opt.Backoff <= 0 {
+		opt.Backoff = 100 * time.Millisecond
+	}
+	if opt.MaxBackoff <= 0 {
+		opt.MaxBackoff = 10 * time.Second
+	}
+	return &supervisor{opt: opt, pending: make(set[ComponentName])}
+}
+
+// 容器启动后才处理致命错误
+func (s *supervisor) start() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ctx == nil {
+		s.ctx, s.cancel = context.WithCancel(context.Background())
+	}
+}
+
+// 容器停止时中断等待中与进行中的重建
+func (s *supervisor) stop() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cancel != nil {
+		s.cancel()
+		s.ctx, s.cancel = nil, nil
+	}
+}
+
+// ReportFatal 报告组件运行时发生了无法恢复的错误，由所在容器的监督者异步重建该组件。
+// 匿名组件的错误无法单独处理，需由所属组件自行上报
+func (c BuildContext) ReportFatal(err error) {
+	container, ok := c.Container.(*ComponentContainer)
+	if !ok {
+		return
+	}
+	if c.Config.Name == "" {
+		c.Logger().Error("fatal error reported by anonymous component is ignored", "error", err)
+		return
+	}
+	container.reportFatal(c, err)
+}
+
+func (c *ComponentContainer) reportFatal(ctx BuildContext, err error) {
+	// 已被替换或卸载的组件实例的报告不再处理
+	c.mu.RLock()
+	current, ok := c.components[ctx.Config.Name]
+	c.mu.RUnlock()
+	if ok && current.lazy != nil {
+		current, ok = current.lazy.created()
+	}
+	if !ok || current.BuildContext.owned != ctx.owned {
+		return
+	}
+	ctx.Logger().Error("component reported fatal error", "error", err)
+	if c.supervisor == nil {
+		c.escalate(ctx, err)
+		return
+	}
+
+	s := c.supervisor
+	s.mu.Lock()
+	if _, ok := s.pending[ctx.Config.Name]; ok {
+		s.mu.Unlock()
+		return
+	}
+	if s.ctx == nil {
+		s.mu.Unlock()
+		ctx.Logger().Warn("container is not running, restart skipped")
+		return
+	}
+	now := time.Now()
+	s.restarts = slices.DeleteFunc(s.restarts, func(t time.Time) bool { return now.Sub(t) > s.opt.Period })
+	if len(s.restarts) >= s.opt.MaxRestarts {
+		s.mu.Unlock()
+		c.emit(&Event{Type: EventRestartGaveUp, Time: now, Context: ctx, Err: err, Attempt: s.opt.MaxRestarts})
+		c.escalate(ctx, fmt.Errorf("%w, component %s: %w", ErrRestartIntensityExceeded, ctx.Config.Name, err))
+		return
+	}
+	s.restarts = append(s.restarts, now)
+	attempt := len(s.restarts)
+	s.pending[ctx.Config.Name] = struct{}{}
+	runCtx := s.ctx
+	s.mu.Unlock()
+
+	go c.restart(runCtx, ctx, err, attempt)
+}
+
+// 等待退避时间后按监督策略重建组件，重建失败时视为再次发生致命错误。
+// 容器停止时runCtx被取消，等待中的重建直接放弃，进行中的重建中断并回滚
+func (c *ComponentContainer) restart(runCtx context.Context, ctx BuildContext, cause error, attempt int) {
+	s := c.supervisor
+	done := func() {
+		s.mu.Lock()
+		delete(s.pending, ctx.Config.Name)
+		s.mu.Unlock()
+	}
+	backoff := s.opt.Backoff << (attempt - 1)
+	if backoff > s.opt.MaxBackoff || backoff <= 0 {
+		backoff = s.opt.MaxBackoff
+	}
+	c.emit(&Event{Type: EventRestarting, Time: time.Now(), Context: ctx, Err: cause, Attempt: attempt})
+	timer := time.NewTimer(backoff)
+	select {
+	case <-runCtx.Done():
+		timer.Stop()
+		done()
+		ctx.Logger().Info("container stopped, restart canceled")
+		return
+	case <-timer.C:
+	}
+
+	names := []ComponentName{ctx.Config.Name}
+	if s.opt.Strategy == RestForOne {
+		orders := c.LoadedComponentNames()
+		if i := slices.Index(orders, ctx.Config.Name); i >= 0 {
+			c.mu.RLock()
+			names = slices.DeleteFunc(slices.Clone(orders[i:]), func(name ComponentName) bool {
+				_, declared := c.configs[name]
+				return !declared
+			})
+			c.mu.RUnlock()
+		}
+	}
+	start := time.Now()
+	err := c.RestartComponentsContext(runCtx, names...)
+	done()
+
+	event := Event{Type: EventRestarted, Time: time.Now(), Context: ctx, Duration: time.Since(start), Err: err, Attempt: attempt}
+	if err != nil {
+		event.Type = EventRestartFailed
+	}
+	c.emit(&event)
+	if err != nil && runCtx.Err() == nil {
+		c.reportFatal(ctx, err)
+	}
+}
+
+// 将无法处理的致命错误上报给父容器，根容器只记录日志
+func (c *ComponentContainer) escalate(ctx BuildContext, err error) {
+	if c.context.Container == nil {
+		c.log().Error("fatal error cannot be handled", "path", formatPath(ctx.GetAbsolutePath()), "error", err)
+		return
+	}
+	c.context.ReportFatal(err)
+}
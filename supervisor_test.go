@@ -0,0 +1,175 @@
+package compcont
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type consumer struct {
+	ctx BuildContext
+}
+
+// 记录组件创建次数的消费者工厂
+func newConsumerFactory(mu *sync.Mutex, created map[string]int) IComponentFactory {
+	return &TypedSimpleComponentFactory[struct{}, *consumer]{
+		TypeID: "consumer",
+		CreateInstanceFunc: func(ctx BuildContext, config struct{}) (instance *consumer, err error) {
+			mu.Lock()
+			created[formatPath(ctx.GetAbsolutePath())]++
+			mu.Unlock()
+			return &consumer{ctx: ctx}, nil
+		},
+	}
+}
+
+// 等待指定类型的事件
+func waitEvent(t *testing.T, container IEventSource, typ EventType) func() Event {
+	ch := make(chan Event, 16)
+	container.Subscribe(func(e *Event) error {
+		if e.Type == typ {
+			ch <- *e
+		}
+		return nil
+	})
+	return func() Event {
+		select {
+		case e := <-ch:
+			return e
+		case <-time.After(time.Second):
+			t.Fatalf("event %s not received", typ)
+			return Event{}
+		}
+	}
+}
+
+func TestSupervisor(t *testing.T) {
+	var mu sync.Mutex
+	created := make(map[string]int)
+	registry := NewFactoryRegistry()
+	MustRegister(registry, newConsumerFactory(&mu, created))
+	configs := []ComponentConfig{
+		{Name: "a", Type: "consumer"},
+		{Name: "b", Type: "consumer", Deps: []ComponentName{"a"}},
+		{Name: "c", Type: "consumer", Deps: []ComponentName{"b"}},
+		{Name: "d", Type: "consumer"},
+	}
+
+	for _, strategy := range []SupervisorStrategy{OneForOne, RestForOne} {
+		clear(created)
+		container := NewComponentContainer(WithFactoryRegistry(registry), WithSupervisor(SupervisorOptions{Strategy: strategy, Backoff: time.Millisecond})).(*ComponentContainer)
+		assert.NoError(t, container.LoadNamedComponents(configs))
+		assert.NoError(t, container.Start(context.Background()))
+		restarted := waitEvent(t, container, EventRestarted)
+
+		orders := container.LoadedComponentNames()
+		b, _ := GetComponent[*consumer](container, "b")
+		b.Instance.ctx.ReportFatal(errors.New("connection lost"))
+		b.Instance.ctx.ReportFatal(errors.New("connection lost")) // 等待重建期间的重复报告被忽略
+		e := restarted()
+		assert.Equal(t, "/b", e.Path())
+		assert.Equal(t, 1, e.Attempt)
+
+		expected := []ComponentName{"b", "c"}
+		if strategy == RestForOne {
+			expected = orders[slices.Index(orders, "b"):]
+		}
+		mu.Lock()
+		for _, name := range orders {
+			count := 1
+			if slices.Contains(expected, name) {
+				count = 2
+			}
+			assert.Equal(t, count, created["/"+name.String()], "%s %s", strategy, name)
+		}
+		mu.Unlock()
+
+		// 已被替换的实例的报告不再处理
+		b.Instance.ctx.ReportFatal(errors.New("late"))
+		time.Sleep(10 * time.Millisecond)
+		mu.Lock()
+		assert.Equal(t, 2, created["/b"])
+		mu.Unlock()
+	}
+}
+
+func TestSupervisorEscalation(t *testing.T) {
+	var mu sync.Mutex
+	created := make(map[string]int)
+	registry := NewFactoryRegistry()
+	MustRegister(registry, newConsumerFactory(&mu, created))
+	MustRegister(registry, &TypedSimpleComponentFactory[containerConfig, IComponentContainer]{
+		TypeID: "supervised",
+		CreateInstanceFunc: func(ctx BuildContext, config containerConfig) (instance IComponentContainer, err error) {
+			instance = NewComponentContainer(WithFactoryRegistry(registry), WithParentContainer(ctx.Container), WithContext(ctx),
+				WithSupervisor(SupervisorOptions{MaxRestarts: 1, Backoff: time.Millisecond}))
+			err = instance.LoadNamedComponents(config.Components)
+			return
+		},
+	})
+	container := NewComponentContainer(WithFactoryRegistry(registry), WithSupervisor(SupervisorOptions{Backoff: time.Millisecond})).(*ComponentContainer)
+	err := container.LoadNamedComponents([]ComponentConfig{
+		{Name: "workers", Type: "supervised", Config: containerConfig{Components: []ComponentConfig{
+			{Name: "w", Type: "consumer"},
+		}}},
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, container.Start(context.Background()))
+	restarted := waitEvent(t, container, EventRestarted)
+	gaveUp := waitEvent(t, container, EventRestartGaveUp)
+
+	report := func() {
+		w, err := Resolve(container, "/workers/w")
+		assert.NoError(t, err)
+		w.Instance.(*consumer).ctx.ReportFatal(errors.New("poison message"))
+	}
+	report()
+	assert.Equal(t, "/workers/w", restarted().Path())
+
+	// 超过重建次数限制后上报给父容器，由父容器重建整个子容器
+	report()
+	assert.Equal(t, "/workers/w", gaveUp().Path())
+	e := restarted()
+	assert.Equal(t, "/workers", e.Path())
+	mu.Lock()
+	assert.Equal(t, 3, created["/workers/w"])
+	mu.Unlock()
+}
+
+func TestSupervisorStopped(t *testing.T) {
+	var mu sync.Mutex
+	created := make(map[string]int)
+	registry := NewFactoryRegistry()
+	MustRegister(registry, newConsumerFactory(&mu, created))
+	container := NewComponentContainer(WithFactoryRegistry(registry), WithSupervisor(SupervisorOptions{Backoff: time.Hour, MaxBackoff: time.Hour})).(*ComponentContainer)
+	assert.NoError(t, container.LoadNamedComponents([]ComponentConfig{{Name: "a", Type: "consumer"}}))
+	a, _ := GetComponent[*consumer](container, "a")
+
+	// 容器未启动时不重建
+	a.Instance.ctx.ReportFatal(errors.New("connection lost"))
+
+	// 容器停止时放弃等待中的重建
+	assert.NoError(t, container.Start(context.Background()))
+	restarting := waitEvent(t, container, EventRestarting)
+	a.Instance.ctx.ReportFatal(errors.New("connection lost"))
+	restarting()
+	assert.NoError(t, container.Stop(context.Background()))
+	assert.Eventually(t, func() bool {
+		container.supervisor.mu.Lock()
+		defer container.supervisor.mu.Unlock()
+		return len(container.supervisor.pending) == 0
+	}, time.Second, time.Millisecond)
+	mu.Lock()
+	assert.Equal(t, 1, created["/a"])
+	mu.Unlock()
+
+	// ctx已取消时不再重建
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.ErrorIs(t, container.RestartComponentsContext(ctx, "a"), context.Canceled)
+}
@@ -0,0 +1,182 @@
+package compcont
+
+import (
+	"context"
+	"maps"
+	"sync"
+	"time"
+)
+
+// 容器产生的span名称
+const (
+	SpanLoad      = "compcont.load"      // 一次LoadNamedComponents调用
+	SpanReconcile = "compcont.reconcile" // 一次热更新
+	SpanCreate    = "compcont.create"    // 组件实例的创建
+	SpanDestroy   = "compcont.destroy"   // 组件实例的销毁
+	SpanStart     = "compcont.start"     // 组件的启动
+	SpanStop      = "compcont.stop"      // 组件的停止
+)
+
+// span的属性名称
+const (
+	AttrPath      = "compcont.path"      // 组件的绝对路径
+	AttrType      = "compcont.type"      // 组件类型
+	AttrContainer = "compcont.container" // 容器的绝对路径
+)
+
+// 创建span时的选项
+type SpanOptions struct {
+	Attributes map[string]string
+	Links      []ISpan // 关联的span，组件创建的span会关联其依赖组件创建时的span
+}
+
+// 追踪的span，由ITracer创建
+type ISpan interface {
+	End(err error) // 结束span，err不为nil时标记为失败
+}
+
+// 追踪器的抽象，父子关系通过ctx传递：构造期间子容器中组件的span是所属组件span的子span，构造结束后的操作挂在调用方传入的ctx下，
+// 匿名组件的span是所属组件span的子span。实现OpenTelemetry适配器时，可在StartSpan中对Links做类型断言以获取其SpanContext
+type ITracer interface {
+	StartSpan(ctx context.Context, name string, opt SpanOptions) (context.Context, ISpan)
+}
+
+// 不做任何事的追踪器，未设置追踪器时使用
+type NoopTracer struct{}
+
+type noopSpan struct{}
+
+func (noopSpan) End(error) {}
+
+// StartSpan implements ITracer.
+func (NoopTracer) StartSpan(ctx context.Context, name string, opt SpanOptions) (context.Context, ISpan) {
+	return ctx, noopSpan{}
+}
+
+// MemoryTracer记录的span
+type RecordedSpan struct {
+	ID         int
+	ParentID   int // 没有父span时为0
+	Name       string
+	Attributes map[string]string
+	Links      []int // 关联的span的ID
+	Start      time.Time
+	End        time.Time
+	Err        error
+	ended      bool
+}
+
+// 在内存中记录所有span的追踪器，用于测试
+type MemoryTracer struct {
+	mu    sync.Mutex
+	spans []*RecordedSpan
+}
+
+type memorySpan struct {
+	tracer *MemoryTracer
+	span   *RecordedSpan
+}
+
+type memorySpanKey struct{}
+
+// StartSpan implements ITracer.
+func (t *MemoryTracer) StartSpan(ctx context.Context, name string, opt SpanOptions) (context.Context, ISpan) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	span := &RecordedSpan{ID: len(t.spans) + 1, Name: name, Attributes: maps.Clone(opt.Attributes), Start: time.Now()}
+	if parent, ok := ctx.Value(memorySpanKey{}).(memorySpan); ok && parent.tracer == t {
+		span.ParentID = parent.span.ID
+	}
+	for _, link := range opt.Links {
+		if link, ok := link.(memorySpan); ok && link.tracer == t {
+			span.Links = append(span.Links, link.span.ID)
+		}
+	}
+	t.spans = append(t.spans, span)
+	s := memorySpan{tracer: t, span: span}
+	return context.WithValue(ctx, memorySpanKey{}, s), s
+}
+
+func (s memorySpan) End(err error) {
+	s.tracer.mu.Lock()
+	defer s.tracer.mu.Unlock()
+	if s.span.ended {
+		return
+	}
+	s.span.End, s.span.Err, s.span.ended = time.Now(), err, true
+}
+
+// 按开始顺序返回所有已结束的span
+func (t *MemoryTracer) Spans() (spans []RecordedSpan) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, span := range t.spans {
+		if span.ended {
+			spans = append(spans, *span)
+		}
+	}
+	return
+}
+
+// 设置容器的追踪器，未设置时继承父容器的追踪器，均未设置时不追踪
+func WithTracer(tracer ITracer) Option {
+	return func(o *options) {
+		o.tracer = tracer
+	}
+}
+
+// 构造期间的context，所有BuildContext的副本共享，构造结束后清空，避免之后的操作挂在早已结束的span下
+type buildContext struct {
+	mu  sync.RWMutex
+	ctx context.Context
+}
+
+func (b *buildContext) end() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.ctx = nil
+}
+
+// 组件构造期间的context，携带了组件创建的span，工厂中发起的调用可据此关联到组件的span。
+// 构造结束后返回context.Background()，之后的操作应通过各方法的ctx参数传入所在操作的context
+func (c BuildContext) Context() context.Context {
+	if c.build == nil {
+		return context.Background()
+	}
+	c.build.mu.RLock()
+	defer c.build.mu.RUnlock()
+	if c.build.ctx == nil {
+		return context.Background()
+	}
+	return c.build.ctx
+}
+
+// 组件span的属性
+func componentSpanOptions(ctx BuildContext) SpanOptions {
+	return SpanOptions{Attributes: map[string]string{
+		AttrPath: formatPath(ctx.GetAbsolutePath()),
+		AttrType: ctx.Config.Type.String(),
+	}}
+}
+
+// 容器span的属性
+func (c *ComponentContainer) spanOptions() SpanOptions {
+	path := containerPath(c)
+	if path == "" {
+		path = "/"
+	}
+	return SpanOptions{Attributes: map[string]string{AttrContainer: path}}
+}
+
+// 依赖组件创建时的span
+func (c *ComponentContainer) depSpans(config ComponentConfig) (spans []ISpan) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, dep := range config.Deps {
+		name, _ := parseDep(dep)
+		if component, ok := c.components[name]; ok && component.BuildContext.span != nil {
+			spans = append(spans, component.BuildContext.span)
+		}
+	}
+	return
+}
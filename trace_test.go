@@ -0,0 +1,77 @@
+package compcont
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTracer(t *testing.T) {
+	var log []string
+	tracer := &MemoryTracer{}
+	registry := NewFactoryRegistry()
+	MustRegister(registry, newLifecycleFactory(&log))
+	MustRegister(registry, newContainerFactory(registry))
+	MustRegister(registry, &TypedSimpleComponentFactory[struct{}, string]{
+		TypeID: "owner",
+		CreateInstanceFunc: func(ctx BuildContext, config struct{}) (instance string, err error) {
+			_, err = ctx.LoadAnonymousComponent(ComponentConfig{Type: "svc"})
+			return
+		},
+	})
+	container := NewComponentContainer(WithFactoryRegistry(registry), WithTracer(tracer)).(*ComponentContainer)
+	err := container.LoadNamedComponents([]ComponentConfig{
+		{Name: "infra", Type: "container", Config: containerConfig{Components: []ComponentConfig{
+			{Name: "db", Type: "svc"},
+		}}},
+		{Name: "api", Type: "owner", Deps: []ComponentName{"infra"}},
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, container.Start(context.Background()))
+
+	spans := tracer.Spans()
+	find := func(name, path string) RecordedSpan {
+		for _, span := range spans {
+			if span.Name == name && span.Attributes[AttrPath] == path {
+				return span
+			}
+		}
+		t.Fatalf("span %s of %s not found", name, path)
+		return RecordedSpan{}
+	}
+	load := spans[0]
+	assert.Equal(t, SpanLoad, load.Name)
+	assert.Equal(t, "/", load.Attributes[AttrContainer])
+
+	// 子容器中组件的span是所属组件span的子span，依赖通过link关联
+	infra := find(SpanCreate, "/infra")
+	assert.Equal(t, load.ID, infra.ParentID)
+	innerLoad := spans[slices.IndexFunc(spans, func(s RecordedSpan) bool { return s.Name == SpanLoad && s.Attributes[AttrContainer] == "/infra" })]
+	assert.Equal(t, infra.ID, innerLoad.ParentID)
+	assert.Equal(t, innerLoad.ID, find(SpanCreate, "/infra/db").ParentID)
+	api := find(SpanCreate, "/api")
+	assert.Equal(t, []int{infra.ID}, api.Links)
+	assert.Equal(t, api.ID, find(SpanCreate, "/").ParentID) // 匿名组件
+
+	// 子容器的启动包含其中组件的启动
+	assert.Equal(t, find(SpanStart, "/infra").ID, find(SpanStart, "/infra/db").ParentID)
+
+	// 构造结束后的操作不再挂在组件创建的span下，而是挂在传入的ctx下
+	infraComponent, err := container.GetComponent("infra")
+	assert.NoError(t, err)
+	assert.Equal(t, context.Background(), infraComponent.BuildContext.Context())
+	inner := infraComponent.Instance.(*ComponentContainer)
+	_, err = inner.LoadAnonymousComponent(ComponentConfig{Type: "svc"})
+	assert.NoError(t, err)
+	parent, span := tracer.StartSpan(context.Background(), "request", SpanOptions{})
+	assert.NoError(t, inner.RestartComponentsContext(parent, "db"))
+	span.End(nil)
+
+	spans = tracer.Spans()
+	assert.Equal(t, 0, find(SpanCreate, "/infra/").ParentID) // 子容器中的匿名组件
+	request := spans[slices.IndexFunc(spans, func(s RecordedSpan) bool { return s.Name == "request" })]
+	reconcile := spans[slices.IndexFunc(spans, func(s RecordedSpan) bool { return s.Name == SpanReconcile })]
+	assert.Equal(t, request.ID, reconcile.ParentID)
+}
@@ -4,10 +4,28 @@ import (
 	"errors"
 	"fmt"
 	"slices"
+	"strings"
 )
 
 type set[T comparable] map[T]struct{}
 
+// 校验一批具名组件的名称并构造map
+func newConfigMap(configs []ComponentConfig) (configMap map[ComponentName]ComponentConfig, err error) {
+	configMap = make(map[ComponentName]ComponentConfig)
+	for _, cfg := range configs {
+		if !cfg.Name.Validate() {
+			err = fmt.Errorf("%w, name: %s, type: %s", ErrComponentNameInvalid, cfg.Name, cfg.Type)
+			return
+		}
+		if _, ok := configMap[cfg.Name]; ok {
+			err = fmt.Errorf("%w, name: %s", ErrComponentAlreadyExists, cfg.Name)
+			return
+		}
+		configMap[cfg.Name] = cfg
+	}
+	return
+}
+
 // 拓扑排序
 func topologicalSort(cfgMap map[ComponentName]set[ComponentName]) ([]ComponentName, error) {
 	// 计算每个节点的入度
@@ -60,11 +78,26 @@ func topologicalSort(cfgMap map[ComponentName]set[ComponentName]) ([]ComponentNa
 	return result, nil
 }
 
+// 解析引用路径，以/开头的为绝对路径，支持.与..
+func parseReferPath(refer string) (findPath []ComponentName, absolute bool, err error) {
+	parts := strings.Split(refer, "/")
+	if parts[0] == "" { // 绝对路径
+		absolute = true
+		parts = parts[1:]
+	}
+	for _, p := range parts {
+		if n := ComponentName(p); p != "." && p != ".." && !n.Validate() {
+			err = fmt.Errorf("%w, in refer %s", ErrComponentNameInvalid, refer)
+			return
+		} else {
+			findPath = append(findPath, n)
+		}
+	}
+	return
+}
+
 // 从当前节点定位一个组件的上下文
 func find(currentNode IComponentContainer, findPath []ComponentName, absolute bool) (ctx BuildContext, err error) {
-	if slices.Equal(findPath, []ComponentName{"yohe", "base", "session_provider"}) {
-		fmt.Println("debug")
-	}
 	// 如果是绝对路径，将currentNode指针指向容器树的根节点
 	if absolute {
 		for {
@@ -0,0 +1,133 @@
+package compcont
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+)
+
+// 组件配置校验发现的问题
+type ValidationIssue struct {
+	Name    ComponentName `json:"name,omitempty"` // 出现问题的组件，整体性的问题为空
+	Field   string        `json:"field"`          // 出现问题的配置字段，如name、type、deps、config
+	Message string        `json:"message"`
+}
+
+func (i ValidationIssue) String() string {
+	if i.Name == "" {
+		return fmt.Sprintf("%s: %s", i.Field, i.Message)
+	}
+	return fmt.Sprintf("%s.%s: %s", i.Name, i.Field, i.Message)
+}
+
+// 校验配置时的选项
+type validateOptions struct {
+	allowUnknownTypes bool
+}
+
+type validateOptionsFunc func(o *validateOptions)
+
+// 未注册的组件类型不视为问题，并跳过其配置的校验，用于没有链接组件工厂的命令行工具
+func WithAllowUnknownTypes() validateOptionsFunc {
+	return func(o *validateOptions) {
+		o.allowUnknownTypes = true
+	}
+}
+
+// ValidateConfigs 在不创建组件实例的情况下校验一批具名组件的配置，返回发现的所有问题：
+// 名称是否合法且唯一、类型是否已注册、组件配置能否被工厂解码（工厂需实现IConfigValidator）、
+// 引用路径、作用域与when表达式是否合法、依赖是否存在以及是否存在循环依赖。
+// when表达式按容器的profile与当前环境变量求值，被禁用的组件只校验自身配置
+func (c *ComponentContainer) ValidateConfigs(configs []ComponentConfig, opts ...validateOptionsFunc) (issues []ValidationIssue) {
+	var opt validateOptions
+	for _, o := range opts {
+		o(&opt)
+	}
+	report := func(name ComponentName, field, format string, args ...any) {
+		issues = append(issues, ValidationIssue{Name: name, Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	env := c.conditionEnv()
+	configMap := make(map[ComponentName]ComponentConfig)
+	disabled := make(set[ComponentName])
+	for _, cfg := range configs {
+		if !cfg.Name.Validate() {
+			report(cfg.Name, "name", "invalid component name %q", cfg.Name)
+			continue
+		}
+		if _, ok := configMap[cfg.Name]; ok {
+			report(cfg.Name, "name", "duplicate component name")
+			continue
+		}
+		configMap[cfg.Name] = cfg
+
+		switch {
+		case cfg.Type != "":
+			factory, err := c.getFactory(cfg.Type)
+			if err != nil {
+				if !opt.allowUnknownTypes || !errors.Is(err, ErrComponentTypeNotRegistered) {
+					report(cfg.Name, "type", "%s", err)
+				}
+			} else if validator, ok := factory.(IConfigValidator); ok {
+				if err = validator.ValidateConfig(cfg.Config); err != nil {
+					report(cfg.Name, "config", "%s", err)
+				}
+			}
+		case cfg.Refer != "":
+			if _, _, err := parseReferPath(cfg.Refer); err != nil {
+				report(cfg.Name, "refer", "%s", err)
+			}
+		default:
+			report(cfg.Name, "type", "either type or refer is required")
+		}
+		if !cfg.Scope.Validate() {
+			report(cfg.Name, "scope", "unknown scope %q", cfg.Scope)
+		}
+		if enabled, err := env.enabled(cfg); err != nil {
+			report(cfg.Name, "when", "%s", err)
+		} else if !enabled {
+			disabled[cfg.Name] = struct{}{}
+		}
+	}
+
+	// 校验启用的组件之间的依赖关系
+	for name := range disabled {
+		delete(configMap, name)
+	}
+	inferGroupDeps(c.factoryRegistry, configMap, nil)
+	for _, name := range slices.Sorted(maps.Keys(configMap)) {
+		cfg := configMap[name]
+		for _, dep := range cfg.Deps {
+			target, optional := parseDep(dep)
+			depCfg, exists := configMap[target]
+			_, isDisabled := disabled[target]
+			switch {
+			case !target.Validate():
+				report(name, "deps", "invalid dependency name %q", dep)
+			case target == name:
+				report(name, "deps", "component depends on itself")
+			case isDisabled && !optional:
+				report(name, "deps", "depends on disabled component %s", target)
+			case !exists && !isDisabled && !optional:
+				report(name, "deps", "dependency %s not found", target)
+			case exists && cfg.Scope.isSingleton() && depCfg.Scope == ScopeScoped:
+				report(name, "deps", "singleton component depends on scoped component %s", target)
+			}
+		}
+	}
+
+	// 忽略不存在的依赖与自依赖后检查循环依赖
+	dag := configDAG(configMap, nil)
+	for name, deps := range dag {
+		for dep := range deps {
+			if _, ok := dag[dep]; !ok || dep == name {
+				delete(deps, dep)
+			}
+		}
+	}
+	if _, err := topologicalSort(dag); err != nil {
+		report("", "deps", "%s", err)
+	}
+	return
+}
@@ -0,0 +1,88 @@
+package compcont
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type schemaConfig struct {
+	Addr    string        `ccf:"addr"`
+	Timeout time.Duration `ccf:"timeout"`
+	Tags    []string
+	Pool    struct {
+		Size int `ccf:"size"`
+	} `ccf:"pool"`
+}
+
+func newSchemaRegistry() IFactoryRegistry {
+	registry := NewFactoryRegistry()
+	MustRegister(registry, &TypedSimpleComponentFactory[schemaConfig, string]{TypeID: "server", Description: "http server"})
+	return registry
+}
+
+func TestValidateConfigs(t *testing.T) {
+	container := NewComponentContainer(WithFactoryRegistry(newSchemaRegistry()), WithProfiles("prod")).(*ComponentContainer)
+	issues := container.ValidateConfigs([]ComponentConfig{
+		{Name: "api", Type: "server", Deps: []ComponentName{"db", "cache?", "debug"}, Config: map[string]any{"addr": ":80", "port": 80}},
+		{Name: "api", Type: "server"},
+		{Name: "1x", Type: "server"},
+		{Name: "db", Type: "postgres"},
+		{Name: "debug", Type: "server", When: "profile == dev"},
+		{Name: "a", Refer: "/b", Deps: []ComponentName{"b"}},
+		{Name: "b", Type: "server", Deps: []ComponentName{"a"}, Scope: "request"},
+	})
+	var fields []string
+	for _, issue := range issues {
+		fields = append(fields, issue.Name.String()+"."+issue.Field)
+	}
+	assert.Equal(t, []string{"api.config", "api.name", "1x.name", "db.type", "b.scope", "api.deps", ".deps"}, fields)
+	assert.Contains(t, issues[0].Message, "invalid keys: port")
+	assert.Equal(t, "api.deps: depends on disabled component debug", issues[5].String())
+	assert.Equal(t, "deps: circular dependency detected", issues[6].String())
+
+	assert.Empty(t, container.ValidateConfigs([]ComponentConfig{
+		{Name: "api", Type: "server", Config: map[string]any{"addr": ":80", "timeout": "1s", "pool": map[string]any{"size": 4}}},
+	}))
+}
+
+func TestPlanGraph(t *testing.T) {
+	container := NewComponentContainer(WithFactoryRegistry(newSchemaRegistry())).(*ComponentContainer)
+	graph, err := container.PlanGraph([]ComponentConfig{
+		{Name: "api", Type: "server", Deps: []ComponentName{"db", "cache?"}},
+		{Name: "db", Type: "server"},
+		{Name: "primary", Refer: "./db"},
+		{Name: "debug", Type: "server", When: "profile == dev"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []GraphEdge{
+		{From: "/api", To: "/db", Kind: GraphEdgeDep},
+		{From: "/primary", To: "/db", Kind: GraphEdgeRefer},
+	}, graph.Edges)
+	assert.Len(t, graph.Nodes, 3)
+}
+
+func TestConfigSchema(t *testing.T) {
+	schema := ConfigSchema(reflect.TypeFor[schemaConfig]())
+	assert.Equal(t, map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"addr":    map[string]any{"type": "string"},
+			"timeout": map[string]any{"type": []string{"string", "integer"}},
+			"Tags":    map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+			"pool":    map[string]any{"type": "object", "properties": map[string]any{"size": map[string]any{"type": "integer"}}},
+		},
+	}, schema)
+
+	file, err := ConfigFileSchema(newSchemaRegistry())
+	assert.NoError(t, err)
+	items := file["items"].(map[string]any)
+	assert.Equal(t, map[string]any{"type": "string", "enum": []string{"server"}}, items["properties"].(map[string]any)["type"])
+	assert.Equal(t, map[string]any{"type": "object", "properties": map[string]any{
+		"critical": map[string]any{"type": "boolean"},
+		"liveness": map[string]any{"type": "boolean"},
+	}, "additionalProperties": false}, items["properties"].(map[string]any)["health"])
+	assert.Len(t, items["allOf"], 1)
+}
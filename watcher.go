@@ -0,0 +1,135 @@
+package compcont
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// 监听配置来源的变化，并将变化后的配置以热更新的方式应用到容器上
+type ConfigWatcher struct {
+	container IComponentContainer
+	source    IConfigSource
+	opt       watcherOptions
+}
+
+type watcherOptions struct {
+	interval    time.Duration
+	debounce    time.Duration
+	onReconcile func(plan ReconcilePlan)
+	onError     func(err error)
+}
+
+type watcherOptionsFunc func(o *watcherOptions)
+
+// 轮询配置文件的间隔，仅对NewConfigWatcher创建的文件监听生效
+func WithWatchInterval(interval time.Duration) watcherOptionsFunc {
+	return func(o *watcherOptions) {
+		o.interval = interval
+	}
+}
+
+// 收到变化通知后需要保持静默的时长，用于合并短时间内的多次写入
+func WithWatchDebounce(debounce time.Duration) watcherOptionsFunc {
+	return func(o *watcherOptions) {
+		o.debounce = debounce
+	}
+}
+
+// 热更新成功后的回调
+func OnReconciled(fn func(plan ReconcilePlan)) watcherOptionsFunc {
+	return func(o *watcherOptions) {
+		o.onReconcile = fn
+	}
+}
+
+// 加载或热更新失败时的回调，此时容器仍保持上一次成功的配置
+func OnReconcileError(fn func(err error)) watcherOptionsFunc {
+	return func(o *watcherOptions) {
+		o.onError = fn
+	}
+}
+
+// 监听本地配置文件或目录
+func NewConfigWatcher(container IComponentContainer, paths []string, optFns ...watcherOptionsFunc) *ConfigWatcher {
+	w := NewSourceWatcher(container, nil, optFns...)
+	w.source = &FileConfigSource{Paths: paths, Interval: w.opt.interval}
+	return w
+}
+
+// 监听任意的配置来源
+func NewSourceWatcher(container IComponentContainer, source IConfigSource, optFns ...watcherOptionsFunc) *ConfigWatcher {
+	opt := watcherOptions{
+		interval: time.Second,
+		debounce: 500 * time.Millisecond,
+	}
+	for _, fn := range optFns {
+		fn(&opt)
+	}
+	return &ConfigWatcher{
+		container: container,
+		source:    source,
+		opt:       opt,
+	}
+}
+
+// Run 立即应用一次当前配置，之后持续监听配置来源的变化，直到ctx结束
+func (w *ConfigWatcher) Run(ctx context.Context) error {
+	changes := w.source.Watch(ctx)
+	w.reconcile(ctx)
+
+	var debounce <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-changes:
+			debounce = time.After(w.opt.debounce) // 每次通知都重新计时
+		case <-debounce:
+			debounce = nil
+			w.reconcile(ctx)
+		}
+	}
+}
+
+// 重新读取配置并应用到容器
+func (w *ConfigWatcher) reconcile(ctx context.Context) {
+	configs, err := w.source.Load(ctx)
+	if err != nil {
+		w.reportError(err)
+		return
+	}
+	reconciler, ok := w.container.(IReconciler)
+	if !ok {
+		w.reportError(fmt.Errorf("container %T does not implement IReconciler", w.container))
+		return
+	}
+	plan, err := reconciler.PlanReconcile(configs)
+	if err != nil {
+		w.reportError(err)
+		return
+	}
+	if plan.Empty() {
+		return
+	}
+	if r, ok := reconciler.(interface {
+		ApplyReconcileContext(context.Context, ReconcilePlan) error
+	}); ok { // 热更新的span挂在当前的ctx下
+		err = r.ApplyReconcileContext(ctx, plan)
+	} else {
+		err = reconciler.ApplyReconcile(plan)
+	}
+	if err != nil {
+		w.reportError(err)
+		return
+	}
+	if w.opt.onReconcile != nil {
+		w.opt.onReconcile(plan)
+	}
+}
+
+func (w *ConfigWatcher) reportError(err error) {
+	if w.opt.onError != nil {
+		w.opt.onError(err)
+	}
+}
@@ -0,0 +1,65 @@
+package compcont
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// 等待通道中的值，超时时测试失败
+func receive[T any](t *testing.T, ch <-chan T) T {
+	select {
+	case v := <-ch:
+		return v
+	case <-time.After(time.Second):
+		t.Fatal("value not received")
+		var zero T
+		return zero
+	}
+}
+
+func TestConfigWatcher(t *testing.T) {
+	registry := NewFactoryRegistry()
+	MustRegister(registry, newReconcileFactory(&reconcileRecorder{}))
+	container := NewComponentContainer(WithFactoryRegistry(registry))
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "components.yaml")
+	assert.NoError(t, os.WriteFile(file, []byte("- name: a\n  type: reconcile\n  config:\n    value: \"1\"\n"), 0o644))
+
+	reconciled := make(chan ReconcilePlan, 4)
+	failed := make(chan error, 4)
+	watcher := NewConfigWatcher(container, []string{dir},
+		WithWatchInterval(10*time.Millisecond),
+		WithWatchDebounce(20*time.Millisecond),
+		OnReconciled(func(plan ReconcilePlan) { reconciled <- plan }),
+		OnReconcileError(func(err error) { failed <- err }),
+	)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go watcher.Run(ctx)
+
+	plan := receive(t, reconciled)
+	assert.Equal(t, []ComponentName{"a"}, plan.Added)
+
+	// 原子替换方式更新配置文件
+	writeAtomic := func(content string) {
+		tmp := filepath.Join(dir, ".tmp")
+		assert.NoError(t, os.WriteFile(tmp, []byte(content), 0o644))
+		assert.NoError(t, os.Rename(tmp, file))
+	}
+	writeAtomic("- name: a\n  type: reconcile\n  config:\n    value: \"2\"\n")
+	plan = receive(t, reconciled)
+	assert.Equal(t, []ComponentName{"a"}, plan.Changed)
+
+	// 无效配置不影响已加载的组件
+	writeAtomic("- name: a\n  type: reconcile\n  unknown: true\n")
+	assert.ErrorIs(t, receive(t, failed), ErrComponentConfigInvalid)
+	a, err := GetComponent[string](container, "a")
+	assert.NoError(t, err)
+	assert.Equal(t, "a:2", a.Instance)
+}